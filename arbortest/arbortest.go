@@ -0,0 +1,306 @@
+// Package arbortest captures the events written by an arbor logger in a test and asserts on them.
+//
+// Example:
+//
+//	func TestCharge(t *testing.T) {
+//		logger, logs := arbortest.New(t)
+//		charge(logger, 42)
+//
+//		logs.AssertLogged(arbor.InfoLevel, "charged", "amount", 42)
+//		logs.AssertNoErrors()
+//	}
+package arbortest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/phuslu/log"
+	"github.com/ternarybob/arbor"
+	"github.com/ternarybob/arbor/models"
+	"github.com/ternarybob/arbor/writers"
+)
+
+// recorderWriterName is the name of the recorder in the test logger's registry
+const recorderWriterName = "arbortest"
+
+// Recorder records the events written by a test logger. It is safe for concurrent use.
+type Recorder struct {
+	t       testing.TB
+	mu      sync.Mutex
+	entries []models.LogEvent
+	quiet   bool
+	done    bool // Set once the test has finished; t.Log must not be called after that
+}
+
+// New returns a logger that writes to the returned recorder, at every level, and mirrors each
+// event to t.Log. The logger is bound to a registry of its own, so events from other loggers are
+// not recorded, the globally registered writers see nothing, and writers or channels added with
+// the logger's With*Writer and SetChannel methods stay private to the test. When the test
+// finishes the registry is shut down and the recorder is cleared.
+//
+// Process-wide settings still apply: global hooks, the redactor and per-prefix level spec
+// entries. A Fatal event still exits unless arbor.SetFatalBehavior or arbor.SetExitFunc is used.
+func New(t testing.TB) (arbor.ILogger, *Recorder) {
+	t.Helper()
+
+	r := &Recorder{t: t}
+	registry := arbor.NewWriterRegistry()
+	registry.RegisterWriter(recorderWriterName, r)
+
+	t.Cleanup(func() {
+		arbor.ShutdownRegistry(context.Background(), registry)
+
+		r.mu.Lock()
+		r.done = true
+		r.entries = nil
+		r.mu.Unlock()
+	})
+
+	return arbor.NewLoggerWithRegistry(registry).WithLevel(arbor.TraceLevel), r
+}
+
+// Quiet stops mirroring events to t.Log
+func (r *Recorder) Quiet() *Recorder {
+	r.mu.Lock()
+	r.quiet = true
+	r.mu.Unlock()
+	return r
+}
+
+// Entries returns a copy of the recorded events, oldest first
+func (r *Recorder) Entries() []models.LogEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]models.LogEvent(nil), r.entries...)
+}
+
+// Reset discards the recorded events
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	r.entries = nil
+	r.mu.Unlock()
+}
+
+// Find returns the recorded events at level whose message contains msgSubstring and whose
+// fields include every key/value pair in fields. An empty msgSubstring matches any message.
+func (r *Recorder) Find(level arbor.LogLevel, msgSubstring string, fields ...interface{}) []models.LogEvent {
+	var found []models.LogEvent
+	for _, entry := range r.Entries() {
+		if matches(entry, level.ToLogLevel(), msgSubstring, fields) {
+			found = append(found, entry)
+		}
+	}
+	return found
+}
+
+// AssertLogged fails the test unless an event was logged at level with a message containing
+// msgSubstring and the given fields, passed as key/value pairs:
+//
+//	logs.AssertLogged(arbor.WarnLevel, "retrying", "attempt", 2, "host", "db-1")
+//
+// Numbers match regardless of their type, so 2 matches an int64 or float64 field of 2.
+func (r *Recorder) AssertLogged(level arbor.LogLevel, msgSubstring string, fields ...interface{}) bool {
+	r.t.Helper()
+
+	if problem := checkFields(fields); problem != "" {
+		r.t.Errorf("arbortest: %s", problem)
+		return false
+	}
+	if len(r.Find(level, msgSubstring, fields...)) > 0 {
+		return true
+	}
+
+	r.t.Errorf("arbortest: expected a %s event containing %q%s, got:\n%s",
+		levelName(level.ToLogLevel()), msgSubstring, describeFields(fields), r.describe())
+	return false
+}
+
+// AssertNotLogged fails the test if an event matching level, msgSubstring and fields was logged
+func (r *Recorder) AssertNotLogged(level arbor.LogLevel, msgSubstring string, fields ...interface{}) bool {
+	r.t.Helper()
+
+	if problem := checkFields(fields); problem != "" {
+		r.t.Errorf("arbortest: %s", problem)
+		return false
+	}
+	found := r.Find(level, msgSubstring, fields...)
+	if len(found) == 0 {
+		return true
+	}
+
+	r.t.Errorf("arbortest: expected no %s event containing %q%s, got:\n%s",
+		levelName(level.ToLogLevel()), msgSubstring, describeFields(fields), describeEntries(found))
+	return false
+}
+
+// AssertNoErrors fails the test if an event at error level or above was logged
+func (r *Recorder) AssertNoErrors() bool {
+	r.t.Helper()
+
+	var failures []models.LogEvent
+	for _, entry := range r.Entries() {
+		if entry.Level >= log.ErrorLevel {
+			failures = append(failures, entry)
+		}
+	}
+	if len(failures) == 0 {
+		return true
+	}
+
+	r.t.Errorf("arbortest: expected no errors, got %d:\n%s", len(failures), describeEntries(failures))
+	return false
+}
+
+// WriteEvent records a copy of the event and mirrors it to t.Log
+func (r *Recorder) WriteEvent(logEvent *models.LogEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.done {
+		return nil
+	}
+	r.entries = append(r.entries, *logEvent)
+	if !r.quiet {
+		r.t.Log(formatEntry(*logEvent))
+	}
+	return nil
+}
+
+// Write records events written as JSON, for callers that bypass WriteEvent
+func (r *Recorder) Write(data []byte) (int, error) {
+	var logEvent models.LogEvent
+	if err := json.Unmarshal(data, &logEvent); err != nil {
+		return 0, err
+	}
+	r.WriteEvent(&logEvent)
+	return len(data), nil
+}
+
+// WithLevel is a no-op; the recorder keeps every event its logger writes
+func (r *Recorder) WithLevel(level log.Level) writers.IWriter {
+	return r
+}
+
+func (r *Recorder) GetFilePath() string {
+	return ""
+}
+
+func (r *Recorder) Close() error {
+	return nil
+}
+
+// describe lists every recorded event for a failure message
+func (r *Recorder) describe() string {
+	entries := r.Entries()
+	if len(entries) == 0 {
+		return "  (no events)"
+	}
+	return describeEntries(entries)
+}
+
+func describeEntries(entries []models.LogEvent) string {
+	lines := make([]string, len(entries))
+	for i, entry := range entries {
+		lines[i] = "  " + formatEntry(entry)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatEntry renders an event on one line: level, prefix, message, error and sorted fields
+func formatEntry(entry models.LogEvent) string {
+	var b strings.Builder
+	b.WriteString(levelName(entry.Level))
+	if entry.Prefix != "" {
+		b.WriteString(" [" + entry.Prefix + "]")
+	}
+	b.WriteString(" " + entry.Message)
+	if entry.Error != "" {
+		fmt.Fprintf(&b, " error=%q", entry.Error)
+	}
+	if entry.CorrelationID != "" {
+		fmt.Fprintf(&b, " correlationid=%s", entry.CorrelationID)
+	}
+
+	keys := make([]string, 0, len(entry.Fields))
+	for key := range entry.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&b, " %s=%v", key, entry.Fields[key])
+	}
+	return b.String()
+}
+
+func levelName(level log.Level) string {
+	return strings.ToUpper(level.String())
+}
+
+// checkFields reports a malformed key/value list
+func checkFields(fields []interface{}) string {
+	if len(fields)%2 != 0 {
+		return fmt.Sprintf("fields must be key/value pairs, got %d values", len(fields))
+	}
+	for i := 0; i < len(fields); i += 2 {
+		if _, ok := fields[i].(string); !ok {
+			return fmt.Sprintf("field key %v at position %d is not a string", fields[i], i)
+		}
+	}
+	return ""
+}
+
+func describeFields(fields []interface{}) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	pairs := make([]string, 0, len(fields)/2)
+	for i := 0; i+1 < len(fields); i += 2 {
+		pairs = append(pairs, fmt.Sprintf("%v=%v", fields[i], fields[i+1]))
+	}
+	return " with " + strings.Join(pairs, " ")
+}
+
+func matches(entry models.LogEvent, level log.Level, msgSubstring string, fields []interface{}) bool {
+	if entry.Level != level || !strings.Contains(entry.Message, msgSubstring) {
+		return false
+	}
+	for i := 0; i+1 < len(fields); i += 2 {
+		key, _ := fields[i].(string)
+		value, exists := entry.Fields[key]
+		if !exists || !valuesEqual(value, fields[i+1]) {
+			return false
+		}
+	}
+	return true
+}
+
+// valuesEqual compares field values, treating numbers of different types as equal when their values are
+func valuesEqual(actual, expected interface{}) bool {
+	if reflect.DeepEqual(actual, expected) {
+		return true
+	}
+	a, aOK := toFloat(actual)
+	e, eOK := toFloat(expected)
+	return aOK && eOK && a == e
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,172 @@
+package arbortest
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/arbor"
+	"github.com/ternarybob/arbor/models"
+)
+
+// fakeTB records failures and log lines instead of failing the test running it
+type fakeTB struct {
+	testing.TB
+	mu       sync.Mutex
+	errors   []string
+	logs     []string
+	cleanups []func()
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Errorf(format string, args ...interface{}) {
+	f.mu.Lock()
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+	f.mu.Unlock()
+}
+
+func (f *fakeTB) Log(args ...interface{}) {
+	f.mu.Lock()
+	f.logs = append(f.logs, fmt.Sprint(args...))
+	f.mu.Unlock()
+}
+
+func (f *fakeTB) Cleanup(fn func()) {
+	f.cleanups = append(f.cleanups, fn)
+}
+
+// finish runs the registered cleanups, as the testing package does when a test ends
+func (f *fakeTB) finish() {
+	for i := len(f.cleanups) - 1; i >= 0; i-- {
+		f.cleanups[i]()
+	}
+}
+
+func TestNew_RecordsAndMirrorsEvents(t *testing.T) {
+	tb := &fakeTB{}
+	logger, logs := New(tb)
+
+	logger.WithPrefix("billing").Debug().Str("invoice", "inv-1").Int("amount", 42).Msg("charged card")
+	logger.Trace().Msg("tracing")
+
+	entries := logs.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("Expected every level to be recorded, got %d events", len(entries))
+	}
+	if entries[0].Message != "charged card" || entries[0].Prefix != "billing" {
+		t.Errorf("Unexpected first event: %+v", entries[0])
+	}
+
+	if len(tb.logs) != 2 || tb.logs[0] != "DEBUG [billing] charged card amount=42 invoice=inv-1" {
+		t.Errorf("Expected events mirrored to t.Log, got %q", tb.logs)
+	}
+
+	logs.AssertLogged(arbor.DebugLevel, "charged", "invoice", "inv-1", "amount", int64(42))
+	logs.AssertLogged(arbor.DebugLevel, "", "amount", 42.0)
+	logs.AssertNotLogged(arbor.InfoLevel, "charged")
+	logs.AssertNoErrors()
+	if len(tb.errors) != 0 {
+		t.Errorf("Expected the assertions to pass, got %q", tb.errors)
+	}
+}
+
+func TestNew_IsolatedFromRegistry(t *testing.T) {
+	_, registered := New(t)
+	registered.Quiet()
+	arbor.RegisterWriter("arbortest-registered", registered)
+	defer arbor.UnregisterWriter("arbortest-registered")
+
+	tb := &fakeTB{}
+	defer tb.finish()
+	logger, logs := New(tb)
+	logger.Info().Msg("private")
+	arbor.NewLogger().Info().Msg("global")
+
+	if len(logs.Entries()) != 1 {
+		t.Errorf("Expected only the test logger's event, got %d", len(logs.Entries()))
+	}
+	if len(registered.Entries()) != 1 || registered.Entries()[0].Message != "global" {
+		t.Errorf("Expected the registered writers not to see the test logger's events, got %+v", registered.Entries())
+	}
+
+	// Channels added by the test logger stay private to the test
+	ch := make(chan []models.LogEvent, 1)
+	logger.SetChannelWithBuffer("arbortest-channel", ch, 1, 10*time.Millisecond)
+	if arbor.GetRegisteredWriter("arbortest-channel") != nil {
+		t.Errorf("Expected the channel writer not to be registered globally")
+	}
+	logger.Info().Msg("to channel")
+
+	select {
+	case batch := <-ch:
+		if len(batch) != 1 || batch[0].Message != "to channel" {
+			t.Errorf("Unexpected batch %+v", batch)
+		}
+	case <-time.After(time.Second):
+		t.Error("Expected the test logger's channel to receive the event")
+	}
+}
+
+func TestRecorder_FailedAssertions(t *testing.T) {
+	tb := &fakeTB{}
+	logger, logs := New(tb)
+	logs.Quiet()
+
+	logger.Warn().Str("host", "db-1").Msg("retrying")
+	logger.Error().Err(errors.New("connection refused")).Msg("query failed")
+
+	if logs.AssertLogged(arbor.WarnLevel, "retrying", "host", "db-2") {
+		t.Error("Expected a mismatched field to fail")
+	}
+	if logs.AssertLogged(arbor.InfoLevel, "retrying") {
+		t.Error("Expected a mismatched level to fail")
+	}
+	if logs.AssertLogged(arbor.WarnLevel, "retrying", "host") {
+		t.Error("Expected an odd number of field values to fail")
+	}
+	if logs.AssertNotLogged(arbor.WarnLevel, "retry") {
+		t.Error("Expected AssertNotLogged to fail for a logged event")
+	}
+	if logs.AssertNoErrors() {
+		t.Error("Expected AssertNoErrors to fail")
+	}
+
+	if len(tb.errors) != 5 {
+		t.Fatalf("Expected 5 failures, got %q", tb.errors)
+	}
+	if !strings.Contains(tb.errors[0], "WARN retrying host=db-1") {
+		t.Errorf("Expected the failure to list the recorded events, got %q", tb.errors[0])
+	}
+	if !strings.Contains(tb.errors[4], `ERROR query failed error="connection refused"`) {
+		t.Errorf("Expected the failure to list the errors, got %q", tb.errors[4])
+	}
+	if len(tb.logs) != 0 {
+		t.Errorf("Expected a quiet recorder not to mirror events, got %q", tb.logs)
+	}
+}
+
+func TestRecorder_Cleanup(t *testing.T) {
+	tb := &fakeTB{}
+	logger, logs := New(tb)
+
+	logger.Info().Msg("during the test")
+	logs.Reset()
+	if len(logs.Entries()) != 0 {
+		t.Errorf("Expected Reset to discard events")
+	}
+
+	logger.Info().Msg("before cleanup")
+	tb.finish()
+	logger.Info().Msg("after the test")
+
+	if len(logs.Entries()) != 0 {
+		t.Errorf("Expected no events after cleanup, got %d", len(logs.Entries()))
+	}
+	if len(tb.logs) != 2 {
+		t.Errorf("Expected no t.Log calls after the test finished, got %q", tb.logs)
+	}
+}
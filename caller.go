@@ -0,0 +1,44 @@
+package arbor
+
+import (
+	"runtime"
+
+	"github.com/ternarybob/arbor/models"
+)
+
+// callerInfo reports the source location skip frames above its caller,
+// using a single runtime.Caller lookup.
+func callerInfo(skip int) *models.CallerInfo {
+	pc, file, line, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return nil
+	}
+	return newCallerInfo(pc, file, line)
+}
+
+// callerFromPC reports the source location of a program counter, such as slog.Record.PC
+func callerFromPC(pc uintptr) *models.CallerInfo {
+	if pc == 0 {
+		return nil
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	if frame.File == "" {
+		return nil
+	}
+	return &models.CallerInfo{
+		File:     frame.File,
+		Line:     frame.Line,
+		Function: frame.Function,
+	}
+}
+
+func newCallerInfo(pc uintptr, file string, line int) *models.CallerInfo {
+	caller := &models.CallerInfo{
+		File: file,
+		Line: line,
+	}
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		caller.Function = fn.Name()
+	}
+	return caller
+}
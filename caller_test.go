@@ -0,0 +1,59 @@
+package arbor
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// logViaWrapper mimics a wrapper library that logs on behalf of its caller
+func logViaWrapper(l ILogger, message string) {
+	l.WithCallerSkip(1).Info().Msg(message)
+}
+
+func TestLogEvent_Caller(t *testing.T) {
+	emitter, capture := newCaptureLogger()
+
+	_, file, line, _ := runtime.Caller(0)
+	emitter.Info().Msg("direct")
+
+	events := capture.Events()
+	require.Len(t, events, 1)
+	require.NotNil(t, events[0].Caller)
+	assert.Equal(t, file, events[0].Caller.File)
+	assert.Equal(t, line+1, events[0].Caller.Line)
+	assert.Equal(t, "github.com/ternarybob/arbor.TestLogEvent_Caller", events[0].Caller.Function)
+	assert.Equal(t, events[0].Caller.Function, events[0].Function, "Function mirrors the caller's function")
+}
+
+func TestLogEvent_CallerSkip(t *testing.T) {
+	emitter, capture := newCaptureLogger()
+
+	_, _, line, _ := runtime.Caller(0)
+	logViaWrapper(emitter, "wrapped")
+	logViaWrapper(emitter.WithPrefix("forked"), "forked")
+
+	events := capture.Events()
+	require.Len(t, events, 2)
+	for i, event := range events {
+		require.NotNil(t, event.Caller)
+		assert.Equal(t, line+1+i, event.Caller.Line)
+		assert.Contains(t, event.Caller.Function, "TestLogEvent_CallerSkip")
+	}
+}
+
+func TestLogEvent_WithoutCaller(t *testing.T) {
+	emitter, capture := newCaptureLogger()
+
+	emitter.WithoutCaller().Info().Msg("no caller")
+	emitter.WithoutCaller().WithPrefix("child").Info().Msg("inherited")
+
+	events := capture.Events()
+	require.Len(t, events, 2)
+	for _, event := range events {
+		assert.Nil(t, event.Caller)
+		assert.Empty(t, event.Function)
+	}
+}
@@ -1,12 +1,20 @@
 package common
 
 import (
+	"errors"
 	"sync"
 	"time"
 
 	"github.com/ternarybob/arbor/models"
 )
 
+// ErrFlushTimeout is returned when a buffered batch could not be sent to its output channel
+// because no consumer received it in time; the batch is dropped.
+var ErrFlushTimeout = errors.New("timed out sending batch to output channel")
+
+// flushSendTimeout bounds how long Flush waits for the consumer to receive a batch
+const flushSendTimeout = 1 * time.Second
+
 // ChannelBuffer provides per-instance batching for log events sent to a channel.
 // Unlike contextbuffer.go which is a singleton, this allows multiple independent buffers.
 type ChannelBuffer struct {
@@ -44,10 +52,16 @@ func NewChannelBuffer(out chan []models.LogEvent, size int, interval time.Durati
 		buffer:        make([]models.LogEvent, 0, size),
 		stopChan:      make(chan struct{}),
 	}
+	cb.wg.Add(1)
 	go cb.run()
 	return cb
 }
 
+// Channel returns the output channel the buffer sends batches to
+func (cb *ChannelBuffer) Channel() chan []models.LogEvent {
+	return cb.outputChan
+}
+
 // Stop signals the buffer to flush any remaining logs and stop.
 func (cb *ChannelBuffer) Stop() {
 	close(cb.stopChan)
@@ -65,8 +79,27 @@ func (cb *ChannelBuffer) Log(event models.LogEvent) {
 	}
 }
 
+// Flush sends any buffered events to the output channel immediately,
+// blocking until the batch is received. Returns ErrFlushTimeout if the send times out.
+func (cb *ChannelBuffer) Flush() error {
+	cb.bufferMux.Lock()
+	if len(cb.buffer) == 0 {
+		cb.bufferMux.Unlock()
+		return nil
+	}
+	logBatch := cb.buffer
+	cb.buffer = make([]models.LogEvent, 0, cb.batchSize)
+	cb.bufferMux.Unlock()
+
+	select {
+	case cb.outputChan <- logBatch:
+		return nil
+	case <-time.After(flushSendTimeout):
+		return ErrFlushTimeout
+	}
+}
+
 func (cb *ChannelBuffer) run() {
-	cb.wg.Add(1)
 	defer cb.wg.Done()
 
 	ticker := time.NewTicker(cb.flushInterval)
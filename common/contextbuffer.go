@@ -20,6 +20,7 @@ var (
 	flushInterval time.Duration
 	stopChan      chan struct{}
 	once          sync.Once
+	stopOnce      sync.Once
 )
 
 // Start initializes and starts the context log buffer.
@@ -36,9 +37,30 @@ func Start(out chan []models.LogEvent, size int, interval time.Duration) {
 }
 
 // Stop signals the buffer to flush any remaining logs and stop.
+// Calling Stop more than once, or before Start, has no effect.
 func Stop() {
 	if stopChan != nil {
-		close(stopChan)
+		stopOnce.Do(func() { close(stopChan) })
+	}
+}
+
+// Flush sends any buffered events to the output channel immediately,
+// blocking until the batch is received. Returns ErrFlushTimeout if the send times out.
+func Flush() error {
+	bufferMux.Lock()
+	if len(buffer) == 0 || outputChan == nil {
+		bufferMux.Unlock()
+		return nil
+	}
+	logBatch := buffer
+	buffer = make([]models.LogEvent, 0, batchSize)
+	bufferMux.Unlock()
+
+	select {
+	case outputChan <- logBatch:
+		return nil
+	case <-time.After(flushSendTimeout):
+		return ErrFlushTimeout
 	}
 }
 
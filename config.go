@@ -0,0 +1,467 @@
+package arbor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/phuslu/log"
+	"github.com/ternarybob/arbor/common"
+	"github.com/ternarybob/arbor/levels"
+	"github.com/ternarybob/arbor/models"
+	"github.com/ternarybob/arbor/writers"
+	"gopkg.in/yaml.v3"
+)
+
+// DEFAULT_CHANNEL_CAPACITY is the number of batches a configured channel holds when no capacity is given
+const DEFAULT_CHANNEL_CAPACITY = 100
+
+// ConfigError lists every problem found in a logger configuration
+type ConfigError struct {
+	Problems []string
+}
+
+func (e *ConfigError) Error() string {
+	if len(e.Problems) == 1 {
+		return "arbor config: " + e.Problems[0]
+	}
+	return fmt.Sprintf("arbor config: %d problems: %s", len(e.Problems), strings.Join(e.Problems, "; "))
+}
+
+// configProblems collects validation problems
+type configProblems []string
+
+func (p *configProblems) add(format string, args ...interface{}) {
+	*p = append(*p, fmt.Sprintf(format, args...))
+}
+
+func (p configProblems) err() error {
+	if len(p) == 0 {
+		return nil
+	}
+	return &ConfigError{Problems: p}
+}
+
+// FromConfig builds a logger from a JSON or YAML document describing a models.LoggerConfiguration.
+// Unknown keys and invalid values are reported as a *ConfigError rather than ignored.
+//
+// Example:
+//
+//	level: info
+//	levelspec: info,db=debug
+//	fields: {service: billing}
+//	writers:
+//	  - {type: console, level: debug}
+//	  - {type: file, filepath: logs/billing.log, outputtype: json}
+//	channels:
+//	  - {name: audit, batchsize: 10, flushinterval: 2s}
+func FromConfig(r io.Reader) (ILogger, error) {
+	config, err := decodeConfig(r)
+	if err != nil {
+		return nil, err
+	}
+	return FromConfiguration(config)
+}
+
+// decodeConfig reads JSON or YAML into a configuration. YAML is converted to JSON first,
+// so both formats share the json tags and strict decoding of the models.
+func decodeConfig(r io.Reader) (models.LoggerConfiguration, error) {
+	var config models.LoggerConfiguration
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return config, fmt.Errorf("arbor config: failed to read configuration: %w", err)
+	}
+
+	var document interface{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return config, &ConfigError{Problems: []string{fmt.Sprintf("invalid JSON or YAML: %v", err)}}
+	}
+	if document == nil {
+		return config, &ConfigError{Problems: []string{"configuration is empty"}}
+	}
+
+	jsonData, err := json.Marshal(document)
+	if err != nil {
+		return config, &ConfigError{Problems: []string{fmt.Sprintf("unsupported configuration: %v", err)}}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return config, &ConfigError{Problems: []string{strings.TrimPrefix(err.Error(), "json: ")}}
+	}
+
+	return config, nil
+}
+
+// FromConfiguration validates the configuration, then registers its writers and channels,
+// applies its levels and redaction and returns a logger with its prefix and fields.
+// Nothing is registered if the configuration is invalid or a writer cannot be created.
+// Writers already registered under the same names are replaced, then flushed and closed.
+//
+// Level becomes the default of the level spec (see SetLevelSpec), so it applies to every logger
+// without a level of its own and can be changed by a reload. Writers without a level use the
+// most verbose of Level and the LevelSpec levels (info if neither is set).
+func FromConfiguration(config models.LoggerConfiguration) (ILogger, error) {
+	if err := validateConfiguration(config); err != nil {
+		return nil, err
+	}
+
+	built, err := buildWriters(config.Writers, defaultWriterLevel(config), openLogStores())
+	if err != nil {
+		return nil, err
+	}
+
+	// Validated above, so the rules compile
+	redactor, _ := buildRedactor(config.Redaction)
+
+	internalLog := common.NewLogger().WithContext("function", "arbor.FromConfiguration").GetLogger()
+
+	replacements := make(map[string]writers.IWriter, len(built))
+	for _, writer := range built {
+		replacements[writer.name] = writer.writer
+	}
+	if err := closeRetiredWriters(SwapWriters(replacements, nil)); err != nil {
+		internalLog.Warn().Err(err).Msg("Replaced writers did not close cleanly")
+	}
+
+	root := NewLogger().(*logger)
+	for _, channel := range config.Channels {
+		capacity := channel.Capacity
+		if capacity == 0 {
+			capacity = DEFAULT_CHANNEL_CAPACITY
+		}
+		root.SetChannelWithBuffer(channel.Name, make(chan []models.LogEvent, capacity), channel.BatchSize, channel.FlushInterval)
+	}
+
+	// Validated above, so the spec parses
+	SetLevelSpec(levelSpecOf(config))
+	if config.Redaction != nil {
+		SetRedactor(redactor)
+	}
+
+	var configured ILogger = root
+	if config.Prefix != "" {
+		configured = configured.WithPrefix(config.Prefix)
+	}
+	configured = configured.WithFields(config.Fields)
+
+	internalLog.Debug().Msgf("Configured %d writer(s) and %d channel(s)", len(built), len(config.Channels))
+	return configured, nil
+}
+
+// validateConfiguration reports every problem in the configuration at once
+func validateConfiguration(config models.LoggerConfiguration) error {
+	var problems configProblems
+
+	if config.Level != "" {
+		if _, err := levels.ParseLevelString(config.Level); err != nil {
+			problems.add("level: %v", err)
+		}
+	}
+	if config.LevelSpec != "" {
+		spec, err := levels.ParseLevelSpec(config.LevelSpec)
+		switch {
+		case err != nil:
+			problems.add("levelspec: %v", err)
+		case config.Level != "" && spec.Default != 0:
+			if level, err := levels.ParseLevelString(config.Level); err == nil && level != spec.Default {
+				problems.add("levelspec: its default level conflicts with level %q", config.Level)
+			}
+		}
+	}
+
+	if _, err := buildRedactor(config.Redaction); err != nil {
+		problems.add("redaction: %v", err)
+	}
+
+	seenTypes := make(map[models.LogWriterType]int)
+	for i, writer := range config.Writers {
+		path := fmt.Sprintf("writers[%d]", i)
+		if writer.Type != "" {
+			path = fmt.Sprintf("writers[%d] (%s)", i, writer.Type)
+		}
+
+		switch writer.Type {
+		case models.LogWriterTypeConsole, models.LogWriterTypeFile, models.LogWriterTypeMemory, models.LogWriterTypeLogStore:
+		case "":
+			problems.add("%s: type is required (console, file, memory or logstore)", path)
+			continue
+		default:
+			problems.add("%s: unknown type %q (expected console, file, memory or logstore)", path, writer.Type)
+			continue
+		}
+
+		if first, exists := seenTypes[writer.Type]; exists {
+			problems.add("%s: duplicate %s writer, already configured by writers[%d]", path, writer.Type, first)
+		} else {
+			seenTypes[writer.Type] = i
+		}
+
+		if writer.Level > levels.PanicLevel {
+			problems.add("%s: unknown level %d", path, writer.Level)
+		}
+
+		isFile := writer.Type == models.LogWriterTypeFile
+		isStore := writer.Type == models.LogWriterTypeMemory || writer.Type == models.LogWriterTypeLogStore
+
+		if isFile && writer.FileName == "" {
+			problems.add("%s: filepath is required", path)
+		}
+		if !isFile {
+			for _, setting := range []struct {
+				key string
+				set bool
+			}{
+				{"filepath", writer.FileName != ""},
+				{"lognameformat", writer.LogNameFormat != ""},
+				{"buffersize", writer.MaxSize != 0},
+				{"maxfiles", writer.MaxBackups != 0},
+				{"outputtype", writer.OutputType != ""},
+			} {
+				if setting.set {
+					problems.add("%s: %s only applies to file writers", path, setting.key)
+				}
+			}
+		}
+		if writer.Type == models.LogWriterTypeLogStore && writer.DBPath == "" {
+			problems.add("%s: dbpath is required; use a memory writer for a store without persistence", path)
+		}
+		if !isStore && writer.DBPath != "" {
+			problems.add("%s: dbpath only applies to memory and logstore writers", path)
+		}
+
+		switch writer.OutputType {
+		case "", models.OutputFormatJSON, models.OutputFormatLogfmt:
+		default:
+			problems.add("%s: unknown outputtype %q (expected json or logfmt)", path, writer.OutputType)
+		}
+		if writer.MaxSize < 0 {
+			problems.add("%s: buffersize must not be negative", path)
+		}
+		if writer.MaxBackups < 0 {
+			problems.add("%s: maxfiles must not be negative", path)
+		}
+
+		if writer.Dedup != nil {
+			if writer.Dedup.Window <= 0 {
+				problems.add("%s: dedup.window must be positive", path)
+			}
+			if writer.Dedup.Burst < 0 {
+				problems.add("%s: dedup.burst must not be negative", path)
+			}
+		}
+	}
+
+	seenChannels := make(map[string]int)
+	for i, channel := range config.Channels {
+		path := fmt.Sprintf("channels[%d]", i)
+		if channel.Name == "" {
+			problems.add("%s: name is required", path)
+		} else if first, exists := seenChannels[channel.Name]; exists {
+			problems.add("%s: duplicate channel %q, already configured by channels[%d]", path, channel.Name, first)
+		} else {
+			seenChannels[channel.Name] = i
+		}
+
+		if channel.BatchSize < 0 {
+			problems.add("%s: batchsize must not be negative", path)
+		}
+		if channel.FlushInterval < 0 {
+			problems.add("%s: flushinterval must not be negative", path)
+		}
+		if channel.Capacity < 0 {
+			problems.add("%s: capacity must not be negative", path)
+		}
+	}
+
+	return problems.err()
+}
+
+// levelSpecOf returns the configuration's level spec, with Level as its default
+func levelSpecOf(config models.LoggerConfiguration) string {
+	if config.Level == "" {
+		return config.LevelSpec
+	}
+
+	spec, err := levels.ParseLevelSpec(config.LevelSpec)
+	if err != nil {
+		return config.LevelSpec
+	}
+	if spec.Default == 0 {
+		spec.Default, _ = levels.ParseLevelString(config.Level)
+	}
+	return spec.String()
+}
+
+// buildRedactor creates the redactor described by the configuration; nil configures no redaction
+func buildRedactor(config *models.RedactionConfiguration) (*Redactor, error) {
+	if config == nil {
+		return nil, nil
+	}
+
+	var action RedactAction
+	switch strings.ToLower(config.Action) {
+	case "", "mask":
+		action = RedactMask
+	case "hash":
+		action = RedactHash
+	case "drop":
+		action = RedactDrop
+	default:
+		return nil, fmt.Errorf("unknown action %q (expected mask, hash or drop)", config.Action)
+	}
+
+	var rules []RedactionRule
+	if config.Defaults {
+		rules = append(rules, DefaultRedactionRules()...)
+	}
+	if len(config.Keys) > 0 {
+		rules = append(rules, SensitiveKeysRule(action, config.Keys...))
+	}
+	for i, pattern := range config.Patterns {
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("patterns[%d]: %v", i, err)
+		}
+		rules = append(rules, RedactionRule{Name: fmt.Sprintf("pattern-%d", i), Pattern: compiled, Action: action})
+	}
+	return NewRedactor(rules...), nil
+}
+
+// defaultWriterLevel is the most verbose level enabled by the configuration's level and level spec
+func defaultWriterLevel(config models.LoggerConfiguration) levels.LogLevel {
+	var verbose log.Level
+	consider := func(level log.Level) {
+		if level > 0 && level <= log.PanicLevel && (verbose == 0 || level < verbose) {
+			verbose = level
+		}
+	}
+
+	if config.Level != "" {
+		if level, err := levels.ParseLevelString(config.Level); err == nil {
+			consider(level)
+		}
+	}
+	if spec, err := levels.ParseLevelSpec(config.LevelSpec); err == nil {
+		consider(spec.Default)
+		for _, level := range spec.Prefixes {
+			consider(level)
+		}
+	}
+
+	if verbose == 0 {
+		return levels.InfoLevel
+	}
+	return levels.FromLogLevel(verbose)
+}
+
+// namedWriter is a writer built from configuration, with the registry name it is registered under
+type namedWriter struct {
+	name   string
+	writer writers.IWriter
+}
+
+// configuredWriterNames returns the registry names of the writers built for a writer type.
+// Stores register the writer that receives events first, then the memory writer that queries them.
+func configuredWriterNames(writerType models.LogWriterType) []string {
+	switch writerType {
+	case models.LogWriterTypeConsole:
+		return []string{WRITER_CONSOLE}
+	case models.LogWriterTypeFile:
+		return []string{WRITER_FILE}
+	case models.LogWriterTypeMemory:
+		return []string{WRITER_MEMORY + "_store", WRITER_MEMORY}
+	case models.LogWriterTypeLogStore:
+		return []string{WRITER_LOGSTORE, WRITER_LOGSTORE + "_memory"}
+	default:
+		return nil
+	}
+}
+
+// openLogStores returns the persisted stores of the registered memory writers by their dbpath
+func openLogStores() map[string]writers.ILogStore {
+	stores := make(map[string]writers.ILogStore)
+	for _, writer := range GetAllRegisteredWriters() {
+		memoryWriter, ok := writer.(writers.IMemoryWriter)
+		if !ok {
+			continue
+		}
+		if store, ok := memoryWriter.GetStore().(writers.IPersistentLogStore); ok && store.GetDBPath() != "" {
+			stores[store.GetDBPath()] = store
+		}
+	}
+	return stores
+}
+
+// buildWriters creates every configured writer. Stores persisted to a dbpath in stores are reused
+// rather than opened again, since BoltDB allows one open handle per file; other persisted stores
+// must open their database. If any writer cannot be created, those already created are closed,
+// leaving reused stores open, and the failures are returned.
+func buildWriters(configs []models.WriterConfiguration, defaultLevel levels.LogLevel, stores map[string]writers.ILogStore) ([]namedWriter, error) {
+	var built []namedWriter
+	var problems configProblems
+	reused := make(map[writers.ILogStore]bool)
+
+	for i, config := range configs {
+		if config.Level == 0 {
+			config.Level = defaultLevel
+		}
+
+		switch config.Type {
+		case models.LogWriterTypeConsole:
+			built = append(built, namedWriter{WRITER_CONSOLE, writers.ConsoleWriter(config)})
+
+		case models.LogWriterTypeFile:
+			if err := os.MkdirAll(filepath.Dir(config.FileName), 0755); err != nil {
+				problems.add("writers[%d] (file): %v", i, err)
+				continue
+			}
+			built = append(built, namedWriter{WRITER_FILE, writers.FileWriter(config)})
+
+		case models.LogWriterTypeMemory, models.LogWriterTypeLogStore:
+			var store writers.ILogStore
+			var err error
+			switch {
+			case config.DBPath == "":
+				store, err = writers.NewInMemoryLogStore(config)
+			case stores[config.DBPath] != nil:
+				store = stores[config.DBPath]
+				reused[store] = true
+			default:
+				store, err = writers.NewPersistentLogStore(config)
+			}
+			if err != nil {
+				problems.add("writers[%d] (%s): %v", i, config.Type, err)
+				continue
+			}
+			if config.DBPath != "" {
+				stores[config.DBPath] = store
+			}
+
+			// The log store writer receives events; the memory writer queries and owns the store
+			names := configuredWriterNames(config.Type)
+			built = append(built,
+				namedWriter{names[0], writers.LogStoreWriter(store, config)},
+				namedWriter{names[1], writers.MemoryWriterWithStore(store, config)},
+			)
+		}
+	}
+
+	if err := problems.err(); err != nil {
+		for _, writer := range built {
+			if memoryWriter, ok := writer.writer.(writers.IMemoryWriter); ok && reused[memoryWriter.GetStore()] {
+				continue
+			}
+			writer.writer.Close()
+		}
+		return nil, err
+	}
+	return built, nil
+}
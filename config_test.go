@@ -0,0 +1,288 @@
+package arbor
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/phuslu/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ternarybob/arbor/models"
+	"github.com/ternarybob/arbor/writers"
+)
+
+// isolateConfig empties the registry and clears the level spec for the test
+func isolateConfig(t *testing.T) {
+	isolateRegistry(t)
+	t.Cleanup(func() {
+		for name := range GetAllRegisteredWriters() {
+			if writer := GetRegisteredWriter(name); writer != nil {
+				writer.Close()
+			}
+		}
+		SetLevelSpec("")
+	})
+}
+
+func TestFromConfig_JSON(t *testing.T) {
+	isolateConfig(t)
+	dir := t.TempDir()
+
+	configured, err := FromConfig(strings.NewReader(`{
+		"level": "debug",
+		"prefix": "billing",
+		"fields": {"service": "billing", "replica": 2},
+		"writers": [
+			{"type": "console", "level": "warn"},
+			{"type": "file", "filepath": "` + filepath.ToSlash(filepath.Join(dir, "logs", "app.log")) + `", "outputtype": "json"},
+			{"type": "memory", "dedup": {"window": "1s"}}
+		],
+		"channels": [
+			{"name": "audit", "batchsize": 2, "flushinterval": "50ms", "capacity": 4}
+		]
+	}`))
+	require.NoError(t, err)
+	t.Cleanup(func() { configured.UnregisterChannel("audit") })
+
+	assert.Equal(t, "debug", GetLevelSpec(), "the level becomes the level spec default")
+	assert.Equal(t, log.WarnLevel, GetRegisteredWriter(WRITER_CONSOLE).(writers.ILevelReporter).GetLevel())
+	assert.Equal(t, log.DebugLevel, GetRegisteredWriter(WRITER_FILE).(writers.ILevelReporter).GetLevel(), "writers without a level follow the logger level")
+	assert.NotNil(t, GetRegisteredMemoryWriter(WRITER_MEMORY))
+	assert.NotNil(t, GetRegisteredWriter(WRITER_MEMORY+"_store"))
+	assert.NotNil(t, GetRegisteredWriter("audit"))
+	assert.DirExists(t, filepath.Join(dir, "logs"))
+
+	configured.Info().Msg("configured")
+
+	select {
+	case batch := <-GetChannel("audit"):
+		require.Len(t, batch, 1)
+		assert.Equal(t, "configured", batch[0].Message)
+		assert.Equal(t, "billing", batch[0].Prefix)
+		assert.Equal(t, "billing", batch[0].Fields["service"])
+		assert.EqualValues(t, 2, batch[0].Fields["replica"])
+	case <-time.After(time.Second):
+		t.Fatal("Expected the configured channel to receive the event")
+	}
+}
+
+func TestFromConfig_YAML(t *testing.T) {
+	isolateConfig(t)
+
+	configured, err := FromConfig(strings.NewReader(`
+level: info
+levelspec: db=trace
+fields:
+  service: billing
+writers:
+  - type: console
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, "info,db=trace", GetLevelSpec())
+	assert.Zero(t, configured.(*logger).level, "the configured logger follows the level spec")
+	assert.Equal(t, log.TraceLevel, GetRegisteredWriter(WRITER_CONSOLE).(writers.ILevelReporter).GetLevel(),
+		"writers without a level allow the most verbose configured level")
+}
+
+func TestFromConfig_ValidationErrors(t *testing.T) {
+	isolateConfig(t)
+
+	_, err := FromConfig(strings.NewReader(`
+level: loud
+redaction:
+  action: scramble
+writers:
+  - type: console
+  - type: console
+  - type: file
+  - type: syslog
+  - type: memory
+    outputtype: json
+  - type: logstore
+channels:
+  - name: audit
+  - name: audit
+    batchsize: -1
+`))
+
+	var configErr *ConfigError
+	require.ErrorAs(t, err, &configErr)
+	assert.Equal(t, []string{
+		"level: unknown level: loud",
+		`redaction: unknown action "scramble" (expected mask, hash or drop)`,
+		"writers[1] (console): duplicate console writer, already configured by writers[0]",
+		"writers[2] (file): filepath is required",
+		`writers[3] (syslog): unknown type "syslog" (expected console, file, memory or logstore)`,
+		"writers[4] (memory): outputtype only applies to file writers",
+		"writers[5] (logstore): dbpath is required; use a memory writer for a store without persistence",
+		`channels[1]: duplicate channel "audit", already configured by channels[0]`,
+		"channels[1]: batchsize must not be negative",
+	}, configErr.Problems)
+	assert.Contains(t, err.Error(), "9 problems")
+
+	assert.Zero(t, GetWriterCount(), "an invalid configuration registers nothing")
+}
+
+func TestFromConfig_LevelConflict(t *testing.T) {
+	isolateConfig(t)
+
+	_, err := FromConfig(strings.NewReader(`{"level": "info", "levelspec": "warn,db=debug"}`))
+	assert.EqualError(t, err, `arbor config: levelspec: its default level conflicts with level "info"`)
+
+	_, err = FromConfig(strings.NewReader(`{"level": "info", "levelspec": "info,db=debug"}`))
+	assert.NoError(t, err)
+}
+
+func TestFromConfig_Redaction(t *testing.T) {
+	isolateConfig(t)
+	t.Cleanup(func() { SetRedactor(nil) })
+	_, capture := newCaptureLogger()
+	RegisterWriter("capture", capture)
+
+	configured, err := FromConfig(strings.NewReader(`
+redaction:
+  keys: [customer]
+  patterns: ['acct-\d+']
+`))
+	require.NoError(t, err)
+
+	configured.WithField("customer_id", "c-42").Info().Msg("charged acct-991")
+
+	events := capture.Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, "charged [REDACTED]", events[0].Message)
+	assert.Equal(t, REDACTED_MASK, events[0].Fields["customer_id"])
+}
+
+func TestFromConfig_DecodeErrors(t *testing.T) {
+	isolateConfig(t)
+
+	for name, document := range map[string]string{
+		"unknown key":   `{"writers": [{"type": "console", "colour": true}]}`,
+		"unknown level": `{"writers": [{"type": "console", "level": "loud"}]}`,
+		"bad duration":  `{"channels": [{"name": "audit", "flushinterval": "soon"}]}`,
+		"empty":         ``,
+		"not a mapping": `- console`,
+	} {
+		_, err := FromConfig(strings.NewReader(document))
+		var configErr *ConfigError
+		assert.ErrorAs(t, err, &configErr, name)
+	}
+
+	_, err := FromConfig(strings.NewReader(`{"writers": [{"type": "console", "colour": true}]}`))
+	assert.ErrorContains(t, err, `unknown field "colour"`)
+}
+
+func TestFromConfig_LogStore(t *testing.T) {
+	isolateConfig(t)
+	dbPath := filepath.Join(t.TempDir(), "logs.db")
+
+	configured, err := FromConfig(strings.NewReader(`{"writers": [{"type": "logstore", "dbpath": "` + filepath.ToSlash(dbPath) + `"}]}`))
+	require.NoError(t, err)
+
+	configured.WithCorrelationId("store-1").Info().Msg("persisted")
+
+	memWriter := GetRegisteredMemoryWriter(WRITER_LOGSTORE + "_memory")
+	require.NotNil(t, memWriter)
+	assert.Eventually(t, func() bool {
+		entries, err := memWriter.GetEntries("store-1")
+		return err == nil && len(entries) == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.FileExists(t, dbPath)
+}
+
+// closeTrackingWriter records whether it has been closed
+type closeTrackingWriter struct {
+	writers.IWriter
+	closed bool
+}
+
+func (w *closeTrackingWriter) Close() error {
+	w.closed = true
+	return w.IWriter.Close()
+}
+
+func TestFromConfig_ClosesReplacedWriters(t *testing.T) {
+	isolateConfig(t)
+
+	_, capture := newCaptureLogger()
+	previous := &closeTrackingWriter{IWriter: capture}
+	RegisterWriter(WRITER_CONSOLE, previous)
+
+	_, err := FromConfig(strings.NewReader(`{"writers": [{"type": "console"}]}`))
+	require.NoError(t, err)
+
+	assert.NotSame(t, previous, GetRegisteredWriter(WRITER_CONSOLE))
+	assert.True(t, previous.closed, "the writer registered under the same name is closed")
+}
+
+func TestFromEnv(t *testing.T) {
+	isolateConfig(t)
+	dir := t.TempDir()
+
+	t.Setenv("APP_LEVEL", "warn")
+	t.Setenv("APP_PREFIX", "worker")
+	t.Setenv("APP_FIELDS", "service=billing, region=eu")
+	t.Setenv("APP_CONSOLE", "true")
+	t.Setenv("APP_CONSOLE_LEVEL", "error")
+	t.Setenv("APP_FILE", filepath.Join(dir, "app.log"))
+	t.Setenv("APP_FILE_FORMAT", "JSON")
+	t.Setenv("APP_FILE_MAXFILES", "3")
+	t.Setenv("APP_CHANNELS", "audit")
+	t.Setenv("APP_CHANNEL_AUDIT_BATCHSIZE", "1")
+	t.Setenv("APP_CHANNEL_AUDIT_FLUSHINTERVAL", "20ms")
+
+	configured, err := FromEnv("app_")
+	require.NoError(t, err)
+	t.Cleanup(func() { configured.UnregisterChannel("audit") })
+
+	assert.Equal(t, "warn", GetLevelSpec())
+	assert.Equal(t, log.ErrorLevel, GetRegisteredWriter(WRITER_CONSOLE).(writers.ILevelReporter).GetLevel())
+	assert.Equal(t, log.WarnLevel, GetRegisteredWriter(WRITER_FILE).(writers.ILevelReporter).GetLevel())
+
+	configured.Warn().Msg("from env")
+
+	select {
+	case batch := <-GetChannel("audit"):
+		require.Len(t, batch, 1)
+		assert.Equal(t, "worker", batch[0].Prefix)
+		assert.Equal(t, "billing", batch[0].Fields["service"])
+		assert.Equal(t, "eu", batch[0].Fields["region"])
+	case <-time.After(time.Second):
+		t.Fatal("Expected the configured channel to receive the event")
+	}
+}
+
+func TestFromEnv_Errors(t *testing.T) {
+	config, err := configFromEnv("ARBOR", []string{
+		"ARBOR_CONSOLE=maybe",
+		"ARBOR_FILE_LEVEL=debug",
+		"ARBOR_MEMORY=1",
+		"ARBOR_MEMORY_LEVEL=loud",
+		"ARBOR_FIELDS=service",
+		"ARBOR_LEVLE=info",
+		"PATH=/usr/bin",
+	})
+
+	var configErr *ConfigError
+	require.ErrorAs(t, err, &configErr)
+	assert.Equal(t, []string{
+		`ARBOR_FIELDS: "service" is not key=value`,
+		`ARBOR_CONSOLE: invalid boolean "maybe"`,
+		`ARBOR_MEMORY_LEVEL: invalid level "loud"`,
+		"ARBOR_FILE_LEVEL: unknown variable, or its writer or channel is not enabled",
+		"ARBOR_LEVLE: unknown variable, or its writer or channel is not enabled",
+	}, configErr.Problems)
+	require.Len(t, config.Writers, 1)
+	assert.Equal(t, models.LogWriterTypeMemory, config.Writers[0].Type)
+}
+
+func TestFromEnv_DefaultPrefix(t *testing.T) {
+	config, err := configFromEnv("", []string{"ARBOR_LEVEL=debug", "OTHER_LEVEL=trace"})
+	require.NoError(t, err)
+	assert.Equal(t, "debug", config.Level)
+	assert.Empty(t, config.Writers)
+}
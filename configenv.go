@@ -0,0 +1,209 @@
+package arbor
+
+import (
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ternarybob/arbor/levels"
+	"github.com/ternarybob/arbor/models"
+)
+
+// DEFAULT_ENV_PREFIX is the prefix FromEnv uses when none is given
+const DEFAULT_ENV_PREFIX = "ARBOR"
+
+// FromEnv builds a logger from environment variables starting with prefix (ARBOR if empty).
+// With the default prefix the recognised variables are:
+//
+//	ARBOR_LEVEL, ARBOR_LEVELSPEC, ARBOR_PREFIX
+//	ARBOR_FIELDS                     default fields, e.g. service=billing,region=eu
+//	ARBOR_CONSOLE                    true to enable the console writer
+//	ARBOR_CONSOLE_LEVEL, ARBOR_CONSOLE_TIMEFORMAT
+//	ARBOR_FILE                       path of the log file, enabling the file writer
+//	ARBOR_FILE_LEVEL, ARBOR_FILE_TIMEFORMAT, ARBOR_FILE_FORMAT (json or logfmt),
+//	ARBOR_FILE_MAXSIZE, ARBOR_FILE_MAXFILES
+//	ARBOR_MEMORY                     true to enable the memory writer
+//	ARBOR_MEMORY_LEVEL, ARBOR_MEMORY_DBPATH
+//	ARBOR_LOGSTORE                   true to enable the persistent log store
+//	ARBOR_LOGSTORE_LEVEL, ARBOR_LOGSTORE_DBPATH
+//	ARBOR_CHANNELS                   channel names, e.g. audit,metrics
+//	ARBOR_CHANNEL_<NAME>_BATCHSIZE, ARBOR_CHANNEL_<NAME>_FLUSHINTERVAL, ARBOR_CHANNEL_<NAME>_CAPACITY
+//
+// Unknown variables with the prefix, and writer settings without their writer, are reported as errors.
+func FromEnv(prefix string) (ILogger, error) {
+	config, err := configFromEnv(prefix, os.Environ())
+	if err != nil {
+		return nil, err
+	}
+	return FromConfiguration(config)
+}
+
+// envSettings holds the prefixed variables not yet consumed while building a configuration
+type envSettings struct {
+	prefix   string
+	values   map[string]string
+	problems configProblems
+}
+
+// take returns and consumes the variable prefix_name
+func (e *envSettings) take(name string) (string, bool) {
+	key := e.prefix + "_" + name
+	value, exists := e.values[key]
+	delete(e.values, key)
+	return strings.TrimSpace(value), exists
+}
+
+func (e *envSettings) string(name string) string {
+	value, _ := e.take(name)
+	return value
+}
+
+func (e *envSettings) bool(name string) bool {
+	value, exists := e.take(name)
+	if !exists || value == "" {
+		return false
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		e.problems.add("%s_%s: invalid boolean %q", e.prefix, name, value)
+	}
+	return enabled
+}
+
+func (e *envSettings) int(name string) int {
+	value, exists := e.take(name)
+	if !exists || value == "" {
+		return 0
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		e.problems.add("%s_%s: invalid number %q", e.prefix, name, value)
+	}
+	return number
+}
+
+func (e *envSettings) duration(name string) time.Duration {
+	value, exists := e.take(name)
+	if !exists || value == "" {
+		return 0
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		e.problems.add("%s_%s: invalid duration %q", e.prefix, name, value)
+	}
+	return duration
+}
+
+func (e *envSettings) level(name string) levels.LogLevel {
+	value, exists := e.take(name)
+	if !exists || value == "" {
+		return 0
+	}
+	level, err := levels.ParseLevelString(value)
+	if err != nil || level > levels.PanicLevel.ToLogLevel() {
+		e.problems.add("%s_%s: invalid level %q", e.prefix, name, value)
+		return 0
+	}
+	return levels.FromLogLevel(level)
+}
+
+// configFromEnv builds a configuration from environ, a list of KEY=value entries
+func configFromEnv(prefix string, environ []string) (models.LoggerConfiguration, error) {
+	var config models.LoggerConfiguration
+
+	prefix = strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(prefix)), "_")
+	if prefix == "" {
+		prefix = DEFAULT_ENV_PREFIX
+	}
+
+	env := &envSettings{prefix: prefix, values: make(map[string]string)}
+	for _, entry := range environ {
+		key, value, _ := strings.Cut(entry, "=")
+		if strings.HasPrefix(key, prefix+"_") {
+			env.values[key] = value
+		}
+	}
+
+	config.Level, _ = env.take("LEVEL")
+	config.LevelSpec, _ = env.take("LEVELSPEC")
+	config.Prefix, _ = env.take("PREFIX")
+
+	if fields, _ := env.take("FIELDS"); fields != "" {
+		config.Fields = make(map[string]interface{})
+		for _, pair := range strings.Split(fields, ",") {
+			key, value, found := strings.Cut(pair, "=")
+			key = strings.TrimSpace(key)
+			if !found || key == "" {
+				env.problems.add("%s_FIELDS: %q is not key=value", prefix, strings.TrimSpace(pair))
+				continue
+			}
+			config.Fields[key] = strings.TrimSpace(value)
+		}
+	}
+
+	if env.bool("CONSOLE") {
+		config.Writers = append(config.Writers, models.WriterConfiguration{
+			Type:       models.LogWriterTypeConsole,
+			Level:      env.level("CONSOLE_LEVEL"),
+			TimeFormat: env.string("CONSOLE_TIMEFORMAT"),
+		})
+	}
+
+	if path, _ := env.take("FILE"); path != "" {
+		config.Writers = append(config.Writers, models.WriterConfiguration{
+			Type:       models.LogWriterTypeFile,
+			FileName:   path,
+			Level:      env.level("FILE_LEVEL"),
+			TimeFormat: env.string("FILE_TIMEFORMAT"),
+			OutputType: models.OutputFormat(strings.ToLower(env.string("FILE_FORMAT"))),
+			MaxSize:    int64(env.int("FILE_MAXSIZE")),
+			MaxBackups: env.int("FILE_MAXFILES"),
+		})
+	}
+
+	if env.bool("MEMORY") {
+		config.Writers = append(config.Writers, models.WriterConfiguration{
+			Type:   models.LogWriterTypeMemory,
+			Level:  env.level("MEMORY_LEVEL"),
+			DBPath: env.string("MEMORY_DBPATH"),
+		})
+	}
+
+	if env.bool("LOGSTORE") {
+		config.Writers = append(config.Writers, models.WriterConfiguration{
+			Type:   models.LogWriterTypeLogStore,
+			Level:  env.level("LOGSTORE_LEVEL"),
+			DBPath: env.string("LOGSTORE_DBPATH"),
+		})
+	}
+
+	if names, _ := env.take("CHANNELS"); names != "" {
+		for _, name := range strings.Split(names, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			setting := "CHANNEL_" + strings.ToUpper(name) + "_"
+			config.Channels = append(config.Channels, models.ChannelConfiguration{
+				Name:          name,
+				BatchSize:     env.int(setting + "BATCHSIZE"),
+				FlushInterval: env.duration(setting + "FLUSHINTERVAL"),
+				Capacity:      env.int(setting + "CAPACITY"),
+			})
+		}
+	}
+
+	// Anything left is a typo or a setting for a writer or channel that is not enabled
+	remaining := make([]string, 0, len(env.values))
+	for key := range env.values {
+		remaining = append(remaining, key)
+	}
+	sort.Strings(remaining)
+	for _, key := range remaining {
+		env.problems.add("%s: unknown variable, or its writer or channel is not enabled", key)
+	}
+
+	return config, env.problems.err()
+}
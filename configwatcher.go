@@ -0,0 +1,296 @@
+package arbor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/ternarybob/arbor/common"
+	"github.com/ternarybob/arbor/levels"
+	"github.com/ternarybob/arbor/models"
+	"github.com/ternarybob/arbor/writers"
+)
+
+// DEFAULT_CONFIG_POLL_INTERVAL is how often WatchConfig checks the file when no interval is given
+const DEFAULT_CONFIG_POLL_INTERVAL = 2 * time.Second
+
+// retiredWriterDrainTimeout bounds how long a reload waits for replaced writers to flush and close
+const retiredWriterDrainTimeout = 10 * time.Second
+
+// IConfigWatcher reloads a logging configuration file when it changes
+type IConfigWatcher interface {
+	// Reload reads the file and applies it now, whether or not it has changed
+	Reload() error
+
+	// LastError returns the error of the most recent reload, or nil if it succeeded
+	LastError() error
+
+	// Stop stops watching the file; the applied configuration stays in place
+	Stop()
+}
+
+// configWatcher polls a configuration file and applies changes to the registered writers,
+// the level spec and the redactor
+type configWatcher struct {
+	path     string
+	interval time.Duration
+
+	mu      sync.Mutex // Serialises reloads
+	applied models.LoggerConfiguration
+	content []byte
+	lastErr error
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// WatchConfig builds a logger from a JSON or YAML configuration file, as FromConfig does, and
+// polls the file for changes every interval (DEFAULT_CONFIG_POLL_INTERVAL if 0).
+//
+// A change to the levels, the writers or the redaction is applied live:
+//   - level and levelspec replace the level spec, so every logger picks them up on its next event
+//   - a writer whose only change is its level keeps running at the new level
+//   - other changed, added or removed writers are swapped atomically in the registry, so each
+//     event reaches either the old writers or the new ones; the old writers are then flushed and closed
+//   - a replaced memory or logstore writer with the same dbpath keeps its open store; a dbpath
+//     that cannot be opened fails the reload
+//   - redaction rules replace the active redactor
+//
+// Prefix, fields and channels belong to the returned logger and the channels' consumers, so
+// changes to them are logged and take effect on restart. An invalid file is reported by
+// LastError and leaves the current configuration in place.
+//
+// Example:
+//
+//	logger, watcher, err := arbor.WatchConfig("logging.yaml", 5*time.Second)
+//	if err != nil {
+//		return err
+//	}
+//	defer watcher.Stop()
+func WatchConfig(path string, interval time.Duration) (ILogger, IConfigWatcher, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("arbor config: %w", err)
+	}
+
+	config, err := decodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	configured, err := FromConfiguration(config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if interval <= 0 {
+		interval = DEFAULT_CONFIG_POLL_INTERVAL
+	}
+
+	w := &configWatcher{
+		path:     path,
+		interval: interval,
+		applied:  config,
+		content:  content,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go w.poll()
+
+	return configured, w, nil
+}
+
+// poll reloads the file whenever its content changes, until Stop is called
+func (w *configWatcher) poll() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			w.reload(false)
+		}
+	}
+}
+
+func (w *configWatcher) Reload() error {
+	return w.reload(true)
+}
+
+func (w *configWatcher) LastError() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.lastErr
+}
+
+func (w *configWatcher) Stop() {
+	w.stopOnce.Do(func() { close(w.stop) })
+	<-w.done
+}
+
+// reload reads the file and applies it if it changed, or always when forced.
+// A file that fails to apply is not retried until its content changes again.
+func (w *configWatcher) reload(force bool) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	internalLog := common.NewLogger().WithContext("function", "ConfigWatcher.reload").GetLogger()
+
+	content, err := os.ReadFile(w.path)
+	if err != nil {
+		err = fmt.Errorf("arbor config: %w", err)
+		if w.lastErr == nil || w.lastErr.Error() != err.Error() {
+			internalLog.Warn().Err(err).Msgf("Failed to read configuration '%s'", w.path)
+		}
+		w.lastErr = err
+		return err
+	}
+
+	if !force && bytes.Equal(content, w.content) {
+		return nil
+	}
+	w.content = content
+
+	config, err := decodeConfig(bytes.NewReader(content))
+	if err == nil {
+		err = w.apply(config)
+	}
+	w.lastErr = err
+
+	if err != nil {
+		internalLog.Warn().Err(err).Msgf("Configuration '%s' not applied, keeping the current configuration", w.path)
+		return err
+	}
+	internalLog.Info().Msgf("Configuration '%s' reloaded", w.path)
+	return nil
+}
+
+// apply moves from the applied configuration to config. Nothing changes if config is invalid
+// or one of its writers cannot be created.
+func (w *configWatcher) apply(config models.LoggerConfiguration) error {
+	if err := validateConfiguration(config); err != nil {
+		return err
+	}
+
+	internalLog := common.NewLogger().WithContext("function", "ConfigWatcher.apply").GetLogger()
+
+	previous := w.applied
+	previousDefault := defaultWriterLevel(previous)
+	currentDefault := defaultWriterLevel(config)
+
+	previousWriters := make(map[models.LogWriterType]models.WriterConfiguration, len(previous.Writers))
+	for _, writer := range previous.Writers {
+		previousWriters[writer.Type] = writer
+	}
+
+	var rebuild []models.WriterConfiguration
+	levelChanges := make(map[string]levels.LogLevel)
+	for _, writer := range config.Writers {
+		old, exists := previousWriters[writer.Type]
+		delete(previousWriters, writer.Type)
+
+		if !exists || !sameWriterSettings(old, writer) {
+			rebuild = append(rebuild, writer)
+			continue
+		}
+		if level := writerLevelOrDefault(writer, currentDefault); level != writerLevelOrDefault(old, previousDefault) {
+			for _, name := range configuredWriterNames(writer.Type) {
+				levelChanges[name] = level
+			}
+		}
+	}
+
+	// Writer types left in previousWriters are no longer configured
+	var remove []string
+	for writerType := range previousWriters {
+		remove = append(remove, configuredWriterNames(writerType)...)
+	}
+
+	built, err := buildWriters(rebuild, currentDefault, openLogStores())
+	if err != nil {
+		return err
+	}
+	redactor, _ := buildRedactor(config.Redaction)
+
+	replacements := make(map[string]writers.IWriter, len(built))
+	for _, writer := range built {
+		replacements[writer.name] = writer.writer
+	}
+	retired := SwapWriters(replacements, remove)
+
+	for name, level := range levelChanges {
+		if writer := GetRegisteredWriter(name); writer != nil {
+			writer.WithLevel(level.ToLogLevel())
+		}
+	}
+
+	SetLevelSpec(levelSpecOf(config))
+	if config.Redaction != nil || previous.Redaction != nil {
+		SetRedactor(redactor)
+	}
+
+	if config.Prefix != previous.Prefix || !reflect.DeepEqual(config.Fields, previous.Fields) ||
+		!reflect.DeepEqual(config.Channels, previous.Channels) {
+		internalLog.Warn().Msg("Prefix, fields and channel changes take effect on restart")
+	}
+
+	w.applied = config
+
+	if err := closeRetiredWriters(retired); err != nil {
+		internalLog.Warn().Err(err).Msg("Replaced writers did not close cleanly")
+	}
+	return nil
+}
+
+// sameWriterSettings reports whether two writer configurations differ at most in their level
+func sameWriterSettings(a, b models.WriterConfiguration) bool {
+	a.Level = 0
+	b.Level = 0
+	return reflect.DeepEqual(a, b)
+}
+
+// writerLevelOrDefault returns the writer's configured level, or the default when it has none
+func writerLevelOrDefault(config models.WriterConfiguration, defaultLevel levels.LogLevel) levels.LogLevel {
+	if config.Level == 0 {
+		return defaultLevel
+	}
+	return config.Level
+}
+
+// closeRetiredWriters flushes then closes writers removed from the registry, memory writers last.
+// A memory writer whose store a registered writer reuses is flushed but left open.
+func closeRetiredWriters(retired map[string]writers.IWriter) error {
+	ctx, cancel := context.WithTimeout(context.Background(), retiredWriterDrainTimeout)
+	defer cancel()
+
+	inUse := make(map[writers.ILogStore]bool)
+	for _, writer := range GetAllRegisteredWriters() {
+		if memoryWriter, ok := writer.(writers.IMemoryWriter); ok {
+			inUse[memoryWriter.GetStore()] = true
+		}
+	}
+
+	d := &drainer{ctx: ctx}
+	order := shutdownOrder(retired)
+	for _, name := range order {
+		if flusher, ok := retired[name].(writers.IFlusher); ok {
+			d.run(name, flusher.Flush)
+		}
+	}
+	for _, name := range order {
+		if memoryWriter, ok := retired[name].(writers.IMemoryWriter); ok && inUse[memoryWriter.GetStore()] {
+			continue
+		}
+		d.run(name, retired[name].Close)
+	}
+	return d.err()
+}
@@ -0,0 +1,257 @@
+package arbor
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/phuslu/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ternarybob/arbor/writers"
+	"go.etcd.io/bbolt"
+)
+
+// writeConfigFile replaces the content of a configuration file
+func writeConfigFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func watchTestConfig(t *testing.T, content string) (ILogger, IConfigWatcher, string) {
+	t.Helper()
+	isolateConfig(t)
+	t.Cleanup(func() { SetRedactor(nil) })
+
+	path := filepath.Join(t.TempDir(), "logging.yaml")
+	writeConfigFile(t, path, content)
+
+	configured, watcher, err := WatchConfig(path, time.Hour)
+	require.NoError(t, err)
+	t.Cleanup(watcher.Stop)
+	return configured, watcher, path
+}
+
+func TestWatchConfig_ReloadsLevelsAndRedaction(t *testing.T) {
+	configured, watcher, path := watchTestConfig(t, `
+level: warn
+writers:
+  - type: memory
+`)
+
+	memWriter := GetRegisteredMemoryWriter(WRITER_MEMORY)
+	require.NotNil(t, memWriter)
+	_, capture := newCaptureLogger()
+	RegisterWriter("capture", capture)
+
+	configured.Info().Msg("suppressed")
+
+	writeConfigFile(t, path, `
+level: debug
+redaction:
+  keys: [secret]
+writers:
+  - type: memory
+`)
+	require.NoError(t, watcher.Reload())
+	assert.NoError(t, watcher.LastError())
+
+	assert.Equal(t, "debug", GetLevelSpec())
+	assert.Same(t, memWriter, GetRegisteredMemoryWriter(WRITER_MEMORY), "a level change keeps the writer")
+	assert.Equal(t, log.DebugLevel, memWriter.(writers.ILevelReporter).GetLevel())
+	assert.Same(t, capture, GetRegisteredWriter("capture"), "writers not from the configuration are left alone")
+
+	configured.WithField("secret", "hunter2").Debug().Msg("visible")
+
+	events := capture.Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, "visible", events[0].Message)
+	assert.Equal(t, REDACTED_MASK, events[0].Fields["secret"])
+
+	// Removing the redaction section disables redaction again
+	writeConfigFile(t, path, `
+level: debug
+writers:
+  - type: memory
+`)
+	require.NoError(t, watcher.Reload())
+	configured.WithField("secret", "hunter2").Debug().Msg("plain")
+	assert.Equal(t, "hunter2", capture.Events()[1].Fields["secret"])
+}
+
+func TestWatchConfig_Polls(t *testing.T) {
+	isolateConfig(t)
+	path := filepath.Join(t.TempDir(), "logging.json")
+	writeConfigFile(t, path, `{"level": "info"}`)
+
+	_, watcher, err := WatchConfig(path, 10*time.Millisecond)
+	require.NoError(t, err)
+	defer watcher.Stop()
+
+	writeConfigFile(t, path, `{"level": "error", "levelspec": "db=trace"}`)
+	assert.Eventually(t, func() bool {
+		return GetLevelSpec() == "error,db=trace"
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestWatchConfig_InvalidReloadKeepsConfiguration(t *testing.T) {
+	_, watcher, path := watchTestConfig(t, `
+level: info
+writers:
+  - type: memory
+`)
+	memWriter := GetRegisteredWriter(WRITER_MEMORY)
+
+	writeConfigFile(t, path, `
+level: loud
+writers:
+  - type: file
+`)
+	err := watcher.Reload()
+
+	var configErr *ConfigError
+	require.ErrorAs(t, err, &configErr)
+	assert.Len(t, configErr.Problems, 2)
+	assert.Equal(t, err, watcher.LastError())
+	assert.Equal(t, "info", GetLevelSpec())
+	assert.Same(t, memWriter, GetRegisteredWriter(WRITER_MEMORY))
+	assert.Nil(t, GetRegisteredWriter(WRITER_FILE))
+}
+
+func TestWatchConfig_SwapsWritersWithoutLoss(t *testing.T) {
+	dir := t.TempDir()
+	configured, watcher, path := watchTestConfig(t, fmt.Sprintf(`
+writers:
+  - type: file
+    filepath: %s
+    outputtype: json
+`, filepath.ToSlash(filepath.Join(dir, "a", "app.log"))))
+
+	var written atomic.Int64
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; ; i++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				configured.Info().Msgf("event %d-%d", g, i)
+				written.Add(1)
+			}
+		}(g)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	writeConfigFile(t, path, fmt.Sprintf(`
+writers:
+  - type: file
+    filepath: %s
+    outputtype: json
+`, filepath.ToSlash(filepath.Join(dir, "b", "app.log"))))
+	require.NoError(t, watcher.Reload())
+
+	time.Sleep(20 * time.Millisecond)
+	close(stop)
+	wg.Wait()
+	require.NoError(t, GetRegisteredWriter(WRITER_FILE).Close())
+
+	assert.Equal(t, int(written.Load()), countLogLines(t, dir), "every event is written exactly once")
+	assert.Positive(t, countLogLines(t, filepath.Join(dir, "a")))
+	assert.Positive(t, countLogLines(t, filepath.Join(dir, "b")))
+}
+
+func TestWatchConfig_ReloadedLogStoreKeepsPersisting(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.ToSlash(filepath.Join(dir, "logs.db"))
+	configured, watcher, path := watchTestConfig(t, fmt.Sprintf(`
+writers:
+  - type: logstore
+    dbpath: %s
+`, dbPath))
+
+	storeWriter := GetRegisteredWriter(WRITER_LOGSTORE)
+	memWriter := GetRegisteredMemoryWriter(WRITER_LOGSTORE + "_memory")
+	require.NotNil(t, memWriter)
+	configured.WithCorrelationId("reload").Info().Msg("before reload")
+
+	writeConfigFile(t, path, fmt.Sprintf(`
+writers:
+  - type: logstore
+    dbpath: %s
+    timeformat: "15:04:05"
+`, dbPath))
+	require.NoError(t, watcher.Reload())
+	assert.NotSame(t, storeWriter, GetRegisteredWriter(WRITER_LOGSTORE), "the changed writer is replaced")
+
+	reloaded := GetRegisteredMemoryWriter(WRITER_LOGSTORE + "_memory")
+	require.NotNil(t, reloaded)
+	assert.Same(t, memWriter.GetStore(), reloaded.GetStore(), "the open store is reused")
+
+	configured.WithCorrelationId("reload").Info().Msg("after reload")
+
+	// A dbpath that cannot be opened fails the reload and keeps the current writers
+	blocker := filepath.Join(dir, "blocker")
+	writeConfigFile(t, blocker, "")
+	writeConfigFile(t, path, fmt.Sprintf(`
+writers:
+  - type: logstore
+    dbpath: %s
+`, filepath.ToSlash(filepath.Join(blocker, "logs.db"))))
+	assert.Error(t, watcher.Reload())
+	assert.Same(t, reloaded, GetRegisteredMemoryWriter(WRITER_LOGSTORE+"_memory"))
+
+	require.NoError(t, GetRegisteredWriter(WRITER_LOGSTORE).Close())
+	require.NoError(t, reloaded.Close())
+
+	db, err := bbolt.Open(filepath.Join(dir, "logs.db"), 0600, &bbolt.Options{Timeout: time.Second})
+	require.NoError(t, err)
+	defer db.Close()
+
+	var persisted []string
+	require.NoError(t, db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket([]byte(writers.LOG_BUCKET)).ForEach(func(key, value []byte) error {
+			var stored writers.StoredLogEntry
+			if err := json.Unmarshal(value, &stored); err != nil {
+				return err
+			}
+			persisted = append(persisted, stored.LogEvent.Message)
+			return nil
+		})
+	}))
+	assert.ElementsMatch(t, []string{"before reload", "after reload"}, persisted)
+}
+
+// countLogLines counts the lines in the regular files under dir, skipping symlinks
+func countLogLines(t *testing.T, dir string) int {
+	t.Helper()
+	lines := 0
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || !info.Mode().IsRegular() {
+			return err
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			lines++
+		}
+		return scanner.Err()
+	})
+	require.NoError(t, err)
+	return lines
+}
@@ -0,0 +1,101 @@
+package arbor
+
+import (
+	"context"
+
+	"github.com/ternarybob/arbor/models"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// contextKey is an unexported type for keys stored by arbor in a context.Context,
+// preventing collisions with keys defined in other packages.
+type contextKey int
+
+const (
+	correlationIDContextKey contextKey = iota
+	loggerContextKey
+)
+
+// NewContext returns a copy of ctx that carries the given logger.
+// Use FromContext to retrieve it further down the call chain, or attach the context
+// to an event with Ctx to pick up the logger's correlation ID, prefix and context fields.
+//
+// Example:
+//
+//	ctx := arbor.NewContext(r.Context(), arbor.Logger().WithCorrelationId(requestID))
+//	arbor.FromContext(ctx).Info().Msg("Handling request")
+func NewContext(ctx context.Context, l ILogger) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, loggerContextKey, l)
+}
+
+// FromContext returns the logger carried by ctx.
+// If ctx carries no logger, the default logger is returned, tagged with the
+// correlation ID from ContextWithCorrelationID when one is present.
+func FromContext(ctx context.Context) ILogger {
+	if ctx != nil {
+		if l, ok := ctx.Value(loggerContextKey).(ILogger); ok && l != nil {
+			return l
+		}
+	}
+
+	if correlationID := CorrelationIDFromContext(ctx); correlationID != "" {
+		return Logger().WithCorrelationId(correlationID)
+	}
+
+	return Logger()
+}
+
+// ContextWithCorrelationID returns a copy of ctx that carries the given correlation ID.
+// Handlers that accept a context (such as the slog handler) will tag events with it.
+func ContextWithCorrelationID(ctx context.Context, correlationID string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, correlationIDContextKey, correlationID)
+}
+
+// CorrelationIDFromContext returns the correlation ID carried by ctx, or an empty string if none is set.
+// A correlation ID set with ContextWithCorrelationID takes precedence over one on a logger stored with NewContext.
+func CorrelationIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if correlationID, ok := ctx.Value(correlationIDContextKey).(string); ok {
+		return correlationID
+	}
+	if l, ok := ctx.Value(loggerContextKey).(*logger); ok && l != nil {
+		return l.contextData[CORRELATION_ID_KEY]
+	}
+	return ""
+}
+
+// applyContext copies the correlation ID, prefix and context fields carried by ctx onto the event,
+// along with the trace and span IDs of an OpenTelemetry span context.
+// Values from ctx override those of the emitting logger; explicit event fields are kept.
+func applyContext(ctx context.Context, logEvent *models.LogEvent) {
+	if ctx == nil {
+		return
+	}
+
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		logEvent.TraceID = spanContext.TraceID().String()
+		logEvent.SpanID = spanContext.SpanID().String()
+	}
+
+	if ctxLogger, ok := ctx.Value(loggerContextKey).(*logger); ok && ctxLogger != nil {
+		if correlationID, exists := ctxLogger.contextData[CORRELATION_ID_KEY]; exists {
+			logEvent.CorrelationID = correlationID
+		}
+		if prefix, exists := ctxLogger.contextData[PREFIX_KEY]; exists {
+			logEvent.Prefix = prefix
+		}
+		ctxLogger.applyFields(logEvent)
+	}
+
+	if correlationID, ok := ctx.Value(correlationIDContextKey).(string); ok && correlationID != "" {
+		logEvent.CorrelationID = correlationID
+	}
+}
@@ -0,0 +1,98 @@
+package arbor
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestFromContext_ReturnsStoredLogger(t *testing.T) {
+	requestLogger := NewLogger().WithCorrelationId("req-1")
+	ctx := NewContext(context.Background(), requestLogger)
+
+	assert.Same(t, requestLogger.(*logger), FromContext(ctx).(*logger))
+}
+
+func TestFromContext_DefaultsToGlobalLogger(t *testing.T) {
+	assert.Same(t, Logger().(*logger), FromContext(context.Background()).(*logger))
+	assert.NotNil(t, FromContext(nil))
+}
+
+func TestFromContext_UsesContextCorrelationID(t *testing.T) {
+	ctx := ContextWithCorrelationID(context.Background(), "ctx-corr")
+
+	l := FromContext(ctx).(*logger)
+	assert.Equal(t, "ctx-corr", l.contextData[CORRELATION_ID_KEY])
+	assert.Equal(t, "ctx-corr", CorrelationIDFromContext(ctx))
+}
+
+func TestLogEvent_Ctx(t *testing.T) {
+	emitter, capture := newCaptureLogger()
+
+	requestLogger := NewLogger().
+		WithCorrelationId("req-42").
+		WithPrefix("http").
+		WithContext("tenant", "acme")
+	ctx := NewContext(context.Background(), requestLogger)
+
+	emitter.Info().Ctx(ctx).Str("tenant", "explicit").Msg("with context")
+	emitter.Info().Ctx(ContextWithCorrelationID(ctx, "override")).Msg("with override")
+	emitter.Info().Ctx(nil).Msg("nil context")
+
+	events := capture.Events()
+	require.Len(t, events, 3)
+
+	assert.Equal(t, "req-42", events[0].CorrelationID)
+	assert.Equal(t, "http", events[0].Prefix)
+	assert.Equal(t, "explicit", events[0].Fields["tenant"], "explicit event fields take precedence")
+
+	assert.Equal(t, "override", events[1].CorrelationID)
+	assert.Equal(t, "acme", events[1].Fields["tenant"])
+
+	assert.Empty(t, events[2].CorrelationID)
+}
+
+func TestLogEvent_CtxAcrossGoroutines(t *testing.T) {
+	emitter, capture := newCaptureLogger()
+	ctx := NewContext(context.Background(), NewLogger().WithCorrelationId("job-7"))
+
+	done := make(chan struct{})
+	go func(ctx context.Context) {
+		defer close(done)
+		emitter.Info().Ctx(ctx).Msg("from worker")
+	}(ctx)
+	<-done
+
+	events := capture.Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, "job-7", events[0].CorrelationID)
+}
+
+func TestLogEvent_CtxTraceContext(t *testing.T) {
+	emitter, capture := newCaptureLogger()
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	emitter.Info().Ctx(ctx).Msg("traced")
+	slog.New(NewSlogHandler(emitter, nil)).InfoContext(ctx, "traced via slog")
+	emitter.Info().Ctx(context.Background()).Msg("untraced")
+
+	events := capture.Events()
+	require.Len(t, events, 3)
+	for _, event := range events[:2] {
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", event.TraceID, event.Message)
+		assert.Equal(t, "00f067aa0ba902b7", event.SpanID, event.Message)
+	}
+	assert.Empty(t, events[2].TraceID)
+	assert.Empty(t, events[2].SpanID)
+}
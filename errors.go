@@ -0,0 +1,142 @@
+package arbor
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"github.com/ternarybob/arbor/models"
+)
+
+// maxErrorChainLength bounds the number of errors recorded for a single event,
+// guarding against very deep or cyclic Unwrap implementations.
+const maxErrorChainLength = 32
+
+// maxStackDepth is the maximum number of frames captured by Stack()
+const maxStackDepth = 64
+
+// errorChain walks err and everything it wraps, outermost first.
+// Both Unwrap() error and Unwrap() []error (errors.Join, fmt.Errorf with multiple %w) are followed.
+// Returns nil when err wraps nothing, since LogEvent.Error already holds its message.
+func errorChain(err error) []models.ErrorDetail {
+	if err == nil {
+		return nil
+	}
+
+	var chain []models.ErrorDetail
+	var walk func(err error)
+	walk = func(err error) {
+		if err == nil || len(chain) >= maxErrorChainLength {
+			return
+		}
+
+		chain = append(chain, models.ErrorDetail{
+			Message: err.Error(),
+			Type:    fmt.Sprintf("%T", err),
+		})
+
+		switch wrapped := err.(type) {
+		case interface{ Unwrap() error }:
+			walk(wrapped.Unwrap())
+		case interface{ Unwrap() []error }:
+			for _, member := range wrapped.Unwrap() {
+				walk(member)
+			}
+		}
+	}
+	walk(err)
+
+	if len(chain) < 2 {
+		return nil
+	}
+	return chain
+}
+
+// errorStack returns the stack trace carried by err or any error it wraps.
+// Errors created by github.com/pkg/errors (and compatible packages) expose a
+// StackTrace() method returning a slice of frames; the innermost trace is used
+// as it is closest to where the error originated.
+func errorStack(err error) []string {
+	var stack []string
+	var walk func(err error, depth int)
+	walk = func(err error, depth int) {
+		if err == nil || depth >= maxErrorChainLength {
+			return
+		}
+
+		if frames := stackTraceFrames(err); len(frames) > 0 {
+			stack = frames
+		}
+
+		switch wrapped := err.(type) {
+		case interface{ Unwrap() error }:
+			walk(wrapped.Unwrap(), depth+1)
+		case interface{ Unwrap() []error }:
+			for _, member := range wrapped.Unwrap() {
+				walk(member, depth+1)
+			}
+		}
+	}
+	walk(err, 0)
+
+	return stack
+}
+
+// stackTraceFrames formats the result of a StackTrace() method found on err.
+// Reflection is used so arbor does not depend on pkg/errors; frames are expected to
+// implement fmt.Formatter (rendered with %+v) or be program counters.
+func stackTraceFrames(err error) []string {
+	method := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return nil
+	}
+
+	trace := method.Call(nil)[0]
+	if trace.Kind() != reflect.Slice {
+		return nil
+	}
+
+	frames := make([]string, 0, trace.Len())
+	for i := 0; i < trace.Len(); i++ {
+		frame := trace.Index(i)
+		if formatter, ok := frame.Interface().(fmt.Formatter); ok {
+			// pkg/errors renders %+v as "function\n\tfile:line"
+			text := fmt.Sprintf("%+v", formatter)
+			frames = append(frames, strings.Replace(text, "\n\t", " ", 1))
+			continue
+		}
+		if frame.Kind() == reflect.Uintptr {
+			if fn := runtime.FuncForPC(uintptr(frame.Uint()) - 1); fn != nil {
+				file, line := fn.FileLine(uintptr(frame.Uint()) - 1)
+				frames = append(frames, fmt.Sprintf("%s %s:%d", fn.Name(), file, line))
+			}
+		}
+	}
+
+	return frames
+}
+
+// callerStack captures the current goroutine's stack, skipping the given number of frames
+// above callerStack itself. Each frame is rendered as "function file:line".
+func callerStack(skip int) []string {
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(skip+2, pcs)
+	if n == 0 {
+		return nil
+	}
+
+	frames := runtime.CallersFrames(pcs[:n])
+	stack := make([]string, 0, n)
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" && !strings.HasPrefix(frame.Function, "runtime.") {
+			stack = append(stack, fmt.Sprintf("%s %s:%d", frame.Function, frame.File, frame.Line))
+		}
+		if !more {
+			break
+		}
+	}
+
+	return stack
+}
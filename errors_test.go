@@ -0,0 +1,105 @@
+package arbor
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// frame mimics pkg/errors.Frame: a program counter that formats itself with %+v
+type frame uintptr
+
+func (f frame) Format(s fmt.State, verb rune) {
+	pc := uintptr(f) - 1
+	fn := runtime.FuncForPC(pc)
+	file, line := fn.FileLine(pc)
+	fmt.Fprintf(s, "%s\n\t%s:%d", fn.Name(), file, line)
+}
+
+// stackError mimics an error created by pkg/errors.New
+type stackError struct {
+	msg    string
+	frames []frame
+}
+
+func (e *stackError) Error() string       { return e.msg }
+func (e *stackError) StackTrace() []frame { return e.frames }
+
+func newStackError(msg string) error {
+	pcs := make([]uintptr, 8)
+	n := runtime.Callers(1, pcs)
+	frames := make([]frame, n)
+	for i, pc := range pcs[:n] {
+		frames[i] = frame(pc)
+	}
+	return &stackError{msg: msg, frames: frames}
+}
+
+func TestLogEvent_ErrorChain(t *testing.T) {
+	emitter, capture := newCaptureLogger()
+
+	root := errors.New("connection refused")
+	wrapped := fmt.Errorf("load config: %w", fmt.Errorf("dial db: %w", root))
+	emitter.Error().Err(wrapped).Msg("startup failed")
+	emitter.Error().Err(root).Msg("plain error")
+
+	events := capture.Events()
+	require.Len(t, events, 2)
+
+	assert.Equal(t, wrapped.Error(), events[0].Error)
+	require.Len(t, events[0].ErrorChain, 3)
+	assert.Equal(t, wrapped.Error(), events[0].ErrorChain[0].Message)
+	assert.Equal(t, "*fmt.wrapError", events[0].ErrorChain[0].Type)
+	assert.Equal(t, "connection refused", events[0].ErrorChain[2].Message)
+	assert.Equal(t, "*errors.errorString", events[0].ErrorChain[2].Type)
+
+	assert.Equal(t, "plain error", events[1].Message)
+	assert.Empty(t, events[1].ErrorChain, "errors that wrap nothing have no chain")
+}
+
+func TestLogEvent_ErrorChain_Join(t *testing.T) {
+	emitter, capture := newCaptureLogger()
+
+	joined := errors.Join(errors.New("first"), fmt.Errorf("second: %w", errors.New("cause")))
+	emitter.Error().Err(joined).Msg("multiple failures")
+
+	events := capture.Events()
+	require.Len(t, events, 1)
+
+	messages := make([]string, 0, len(events[0].ErrorChain))
+	for _, detail := range events[0].ErrorChain {
+		messages = append(messages, detail.Message)
+	}
+	assert.Equal(t, []string{joined.Error(), "first", "second: cause", "cause"}, messages)
+}
+
+func TestLogEvent_Stack(t *testing.T) {
+	emitter, capture := newCaptureLogger()
+
+	emitter.Error().Err(errors.New("no trace")).Msg("without stack")
+	emitter.Error().Err(errors.New("no trace")).Stack().Msg("call site stack")
+	emitter.Error().Stack().Err(fmt.Errorf("wrapped: %w", newStackError("origin"))).Msg("error stack")
+
+	events := capture.Events()
+	require.Len(t, events, 3)
+
+	assert.Empty(t, events[0].Stack, "stack traces are opt-in")
+
+	require.NotEmpty(t, events[1].Stack)
+	assert.Contains(t, events[1].Stack[0], "TestLogEvent_Stack")
+	assert.Contains(t, events[1].Stack[0], "errors_test.go:")
+
+	require.NotEmpty(t, events[2].Stack)
+	assert.Contains(t, events[2].Stack[0], "newStackError")
+	assert.False(t, strings.Contains(events[2].Stack[0], "\n"), "frames are rendered on a single line")
+}
+
+func TestErrorChain_Nil(t *testing.T) {
+	assert.Nil(t, errorChain(nil))
+	assert.Nil(t, errorStack(nil))
+}
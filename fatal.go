@@ -0,0 +1,97 @@
+package arbor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/phuslu/log"
+	"github.com/ternarybob/arbor/writers"
+)
+
+// FatalBehavior controls what happens after a Fatal or Panic event has been written
+type FatalBehavior int
+
+const (
+	// FatalBehaviorTerminate flushes all writers, then exits the process for Fatal
+	// events and panics with the message for Panic events. This is the default.
+	FatalBehaviorTerminate FatalBehavior = iota
+
+	// FatalBehaviorLogOnly writes Fatal and Panic events like any other level and returns
+	FatalBehaviorLogOnly
+)
+
+// fatalExitCode is the process exit code used after a Fatal event
+const fatalExitCode = 1
+
+// fatalFlushTimeout bounds how long Fatal and Panic wait for writers to flush
+const fatalFlushTimeout = 5 * time.Second
+
+var (
+	fatalMux      sync.RWMutex
+	fatalBehavior = FatalBehaviorTerminate
+	exitFunc      = os.Exit
+)
+
+// SetFatalBehavior sets what Fatal and Panic events do once written.
+// Use FatalBehaviorLogOnly to keep the log-and-return behavior of earlier versions.
+func SetFatalBehavior(behavior FatalBehavior) {
+	fatalMux.Lock()
+	fatalBehavior = behavior
+	fatalMux.Unlock()
+}
+
+// SetExitFunc replaces the function called to exit the process after a Fatal event.
+// Tests can use it to observe Fatal without terminating; nil restores os.Exit.
+func SetExitFunc(fn func(code int)) {
+	fatalMux.Lock()
+	defer fatalMux.Unlock()
+
+	if fn == nil {
+		fn = os.Exit
+	}
+	exitFunc = fn
+}
+
+// terminate applies Fatal and Panic semantics after the event has been written:
+// writers are flushed, then Fatal exits the process and Panic panics with the message.
+func (l *logger) terminate(level log.Level, message string) {
+	if level != log.FatalLevel && level != log.PanicLevel {
+		return
+	}
+
+	fatalMux.RLock()
+	behavior, exit := fatalBehavior, exitFunc
+	fatalMux.RUnlock()
+
+	if behavior == FatalBehaviorLogOnly {
+		return
+	}
+
+	l.flush(fatalFlushTimeout)
+
+	if level == log.FatalLevel {
+		exit(fatalExitCode)
+		return
+	}
+	panic(message)
+}
+
+// flush flushes the logger's writers, then the writers and channel buffers of its registry
+// (see Flush), giving up after timeout so a blocked consumer cannot hang the process.
+func (l *logger) flush(timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	writers.ReportPendingSamples()
+
+	d := &drainer{ctx: ctx}
+	for i, writer := range l.writers {
+		if flusher, ok := writer.(writers.IFlusher); ok {
+			d.run(fmt.Sprintf("writer[%d]", i), flusher.Flush)
+		}
+	}
+	flushRegistered(d, l.writerRegistry())
+}
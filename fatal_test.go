@@ -0,0 +1,82 @@
+package arbor
+
+import (
+	"testing"
+
+	"github.com/phuslu/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ternarybob/arbor/levels"
+	"github.com/ternarybob/arbor/models"
+	"github.com/ternarybob/arbor/writers"
+)
+
+// captureExit replaces the exit function for the duration of a test
+func captureExit(t *testing.T) *[]int {
+	t.Helper()
+	codes := &[]int{}
+	SetExitFunc(func(code int) { *codes = append(*codes, code) })
+	t.Cleanup(func() { SetExitFunc(nil) })
+	return codes
+}
+
+func TestFatal_FlushesThenExits(t *testing.T) {
+	codes := captureExit(t)
+
+	config := models.WriterConfiguration{Level: levels.TraceLevel}
+	memWriter := writers.MemoryWriter(config)
+	defer memWriter.Close()
+	storeWriter := writers.LogStoreWriter(memWriter.GetStore(), config)
+	defer storeWriter.Close()
+
+	var storedAtExit int
+	SetExitFunc(func(code int) {
+		entries, _ := memWriter.GetStore().GetByCorrelation("fatal-1")
+		storedAtExit = len(entries)
+		*codes = append(*codes, code)
+	})
+
+	l := NewLogger().WithWriters([]writers.IWriter{storeWriter}).WithCorrelationId("fatal-1")
+	l.Info().Msg("before")
+	l.Fatal().Msg("cannot continue")
+
+	assert.Equal(t, []int{fatalExitCode}, *codes)
+	assert.Equal(t, 2, storedAtExit, "async writers are flushed before exit")
+}
+
+func TestPanic_FlushesThenPanics(t *testing.T) {
+	emitter, capture := newCaptureLogger()
+
+	assert.PanicsWithValue(t, "unrecoverable", func() {
+		emitter.Panic().Str("component", "db").Msg("unrecoverable")
+	})
+
+	events := capture.Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, log.PanicLevel, events[0].Level)
+}
+
+func TestFatalBehaviorLogOnly(t *testing.T) {
+	codes := captureExit(t)
+	SetFatalBehavior(FatalBehaviorLogOnly)
+	t.Cleanup(func() { SetFatalBehavior(FatalBehaviorTerminate) })
+
+	emitter, capture := newCaptureLogger()
+	assert.NotPanics(t, func() {
+		emitter.Fatal().Msg("logged only")
+		emitter.Panic().Msgf("logged %s", "only")
+	})
+
+	assert.Empty(t, *codes)
+	assert.Len(t, capture.Events(), 2)
+}
+
+func TestFatal_DisabledLevelStillExits(t *testing.T) {
+	codes := captureExit(t)
+	emitter, capture := newCaptureLogger()
+
+	emitter.WithLevel(Disabled).Fatal().Msg("not written")
+
+	assert.Equal(t, []int{fatalExitCode}, *codes, "Fatal exits even when the logger's level drops the event")
+	assert.Empty(t, capture.Events())
+}
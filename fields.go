@@ -0,0 +1,102 @@
+package arbor
+
+import (
+	"time"
+
+	"github.com/ternarybob/arbor/models"
+)
+
+// WithFields returns a fork whose events all include the given fields.
+// Values are stored as given and rendered by each writer; fields set on an event take precedence.
+func (l *logger) WithFields(fields map[string]interface{}) ILogger {
+	if len(fields) == 0 {
+		return l
+	}
+
+	forked := l.fork()
+	for key, value := range fields {
+		forked.setField(key, value)
+	}
+	return forked
+}
+
+// WithField returns a fork whose events all include the given field
+func (l *logger) WithField(key string, value interface{}) ILogger {
+	if key == "" {
+		return l
+	}
+
+	forked := l.fork()
+	forked.setField(key, value)
+	return forked
+}
+
+// WithStr returns a fork whose events all include a string field
+func (l *logger) WithStr(key, value string) ILogger {
+	return l.WithField(key, value)
+}
+
+// WithInt returns a fork whose events all include an integer field
+func (l *logger) WithInt(key string, value int) ILogger {
+	return l.WithField(key, value)
+}
+
+// WithInt64 returns a fork whose events all include an int64 field
+func (l *logger) WithInt64(key string, value int64) ILogger {
+	return l.WithField(key, value)
+}
+
+// WithFloat64 returns a fork whose events all include a float64 field
+func (l *logger) WithFloat64(key string, value float64) ILogger {
+	return l.WithField(key, value)
+}
+
+// WithBool returns a fork whose events all include a boolean field
+func (l *logger) WithBool(key string, value bool) ILogger {
+	return l.WithField(key, value)
+}
+
+// WithDur returns a fork whose events all include a duration field, rendered as a string
+func (l *logger) WithDur(key string, value time.Duration) ILogger {
+	return l.WithField(key, value.String())
+}
+
+// WithTime returns a fork whose events all include a time field
+func (l *logger) WithTime(key string, value time.Time) ILogger {
+	return l.WithField(key, value)
+}
+
+func (l *logger) setField(key string, value interface{}) {
+	if l.fields == nil {
+		l.fields = make(map[string]interface{})
+	}
+
+	l.fields[key] = value
+}
+
+// applyFields adds the logger's fields and WithContext values to the event.
+// Fields already on the event are kept, so event and context.Context values take precedence.
+func (l *logger) applyFields(logEvent *models.LogEvent) {
+	if len(l.fields) == 0 && len(l.contextData) == 0 {
+		return
+	}
+
+	if logEvent.Fields == nil {
+		logEvent.Fields = make(map[string]interface{}, len(l.fields)+len(l.contextData))
+	}
+
+	for key, value := range l.fields {
+		if _, exists := logEvent.Fields[key]; !exists {
+			logEvent.Fields[key] = value
+		}
+	}
+
+	for key, value := range l.contextData {
+		if key == CORRELATION_ID_KEY || key == PREFIX_KEY {
+			continue
+		}
+		if _, exists := logEvent.Fields[key]; !exists {
+			logEvent.Fields[key] = value
+		}
+	}
+}
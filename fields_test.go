@@ -0,0 +1,107 @@
+package arbor
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_WithFields(t *testing.T) {
+	emitter, capture := newCaptureLogger()
+	started := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	fielded := emitter.
+		WithFields(map[string]interface{}{"service": "billing", "version": 3}).
+		WithStr("region", "eu").
+		WithInt("shard", 7).
+		WithInt64("tenant_id", 1<<40).
+		WithFloat64("ratio", 0.5).
+		WithBool("canary", true).
+		WithDur("timeout", 2*time.Second).
+		WithTime("started", started)
+
+	fielded.Info().Msg("first")
+	fielded.Warn().Msg("second")
+
+	events := capture.Events()
+	require.Len(t, events, 2)
+	for _, event := range events {
+		assert.Equal(t, "billing", event.Fields["service"])
+		assert.EqualValues(t, 3, event.Fields["version"])
+		assert.Equal(t, "eu", event.Fields["region"])
+		assert.EqualValues(t, 7, event.Fields["shard"])
+		assert.EqualValues(t, 1<<40, event.Fields["tenant_id"])
+		assert.Equal(t, 0.5, event.Fields["ratio"])
+		assert.Equal(t, true, event.Fields["canary"])
+		assert.Equal(t, "2s", event.Fields["timeout"])
+		assert.Equal(t, started.Format(time.RFC3339), event.Fields["started"])
+	}
+}
+
+func TestLogger_WithFieldsPrecedence(t *testing.T) {
+	emitter, capture := newCaptureLogger()
+
+	requestLogger := NewLogger().WithField("user", "from-context")
+	ctx := NewContext(context.Background(), requestLogger)
+
+	fielded := emitter.WithField("user", "from-logger").WithField("service", "api")
+	fielded.Info().Str("user", "from-event").Msg("event wins")
+	fielded.Info().Ctx(ctx).Msg("context wins")
+	fielded.Info().Msg("logger only")
+
+	events := capture.Events()
+	require.Len(t, events, 3)
+	assert.Equal(t, "from-event", events[0].Fields["user"])
+	assert.Equal(t, "from-context", events[1].Fields["user"])
+	assert.Equal(t, "api", events[1].Fields["service"])
+	assert.Equal(t, "from-logger", events[2].Fields["user"])
+}
+
+func TestLogger_WithContextWritesFields(t *testing.T) {
+	emitter, capture := newCaptureLogger()
+
+	emitter.WithContext("tenant", "acme").WithCorrelationId("corr-1").Info().Msg("with context")
+
+	events := capture.Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, "acme", events[0].Fields["tenant"])
+	assert.Equal(t, "corr-1", events[0].CorrelationID)
+	assert.NotContains(t, events[0].Fields, CORRELATION_ID_KEY, "correlation ID is not duplicated as a field")
+}
+
+func TestLogger_WithFieldsForks(t *testing.T) {
+	emitter, capture := newCaptureLogger()
+
+	parent := emitter.WithInt("attempt", 1)
+	child := parent.Copy().WithInt("attempt", 2).WithBool("retry", true)
+	copied := child.Copy()
+	cleared := child.ClearContext()
+
+	parent.Info().Msg("parent")
+	copied.Info().Msg("copied")
+	cleared.Info().Msg("cleared")
+
+	events := capture.Events()
+	require.Len(t, events, 3)
+	assert.EqualValues(t, 1, events[0].Fields["attempt"])
+	assert.NotContains(t, events[0].Fields, "retry", "child fields must not leak into the parent")
+	assert.EqualValues(t, 2, events[1].Fields["attempt"])
+	assert.Equal(t, true, events[1].Fields["retry"])
+	assert.Empty(t, events[2].Fields)
+}
+
+func TestSlogHandler_LoggerFields(t *testing.T) {
+	emitter, capture := newCaptureLogger()
+	slogger := slog.New(NewSlogHandler(emitter.WithStr("service", "api").WithInt("status", 0), nil))
+
+	slogger.Info("handled", "status", 200)
+
+	events := capture.Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, "api", events[0].Fields["service"])
+	assert.EqualValues(t, 200, events[0].Fields["status"], "record attributes take precedence")
+}
@@ -0,0 +1,116 @@
+package arbor
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/ternarybob/arbor/common"
+	"github.com/ternarybob/arbor/models"
+)
+
+// ILogHook is run on every event after it has been built and before it reaches any writer.
+// A hook may add or change fields, rewrite the message, change the level or drop the event.
+// The event passed to Run is owned by the call and may be mutated freely; it must not be
+// retained after Run returns. Hooks are called concurrently and must be safe for concurrent use.
+type ILogHook interface {
+	// Run processes the event; returning false drops it
+	Run(event *models.LogEvent) bool
+}
+
+// LogHookFunc adapts an ordinary function to ILogHook
+type LogHookFunc func(event *models.LogEvent) bool
+
+// Run calls f(event)
+func (f LogHookFunc) Run(event *models.LogEvent) bool {
+	return f(event)
+}
+
+var (
+	globalHooks    []ILogHook
+	globalHooksMux sync.RWMutex
+)
+
+// AddHook registers a hook that runs for every logger.
+// Global hooks run in registration order, before any hooks added with WithHook.
+func AddHook(hook ILogHook) {
+	if hook == nil {
+		return
+	}
+
+	globalHooksMux.Lock()
+	defer globalHooksMux.Unlock()
+
+	// Copy on write so running hook chains never see a partially updated slice
+	hooks := make([]ILogHook, len(globalHooks), len(globalHooks)+1)
+	copy(hooks, globalHooks)
+	globalHooks = append(hooks, hook)
+}
+
+// ClearHooks removes all hooks registered with AddHook
+func ClearHooks() {
+	globalHooksMux.Lock()
+	globalHooks = nil
+	globalHooksMux.Unlock()
+}
+
+// WithHook returns a fork that runs hook on its events, after the global hooks and
+// any hooks added to the loggers it was forked from.
+func (l *logger) WithHook(hook ILogHook) ILogger {
+	forked := l.fork()
+	if hook != nil {
+		forked.hooks = append(forked.hooks, hook)
+	}
+	return forked
+}
+
+// hasHooks reports whether any global or logger hooks are installed
+func (l *logger) hasHooks() bool {
+	if len(l.hooks) > 0 {
+		return true
+	}
+	globalHooksMux.RLock()
+	defer globalHooksMux.RUnlock()
+	return len(globalHooks) > 0
+}
+
+// runHooks runs the global hooks then the logger's hooks in order.
+// Returns false if a hook dropped the event.
+func (l *logger) runHooks(logEvent *models.LogEvent) bool {
+	globalHooksMux.RLock()
+	hooks := globalHooks
+	globalHooksMux.RUnlock()
+
+	if len(hooks) == 0 && len(l.hooks) == 0 {
+		return true
+	}
+
+	if logEvent.Fields == nil {
+		logEvent.Fields = make(map[string]interface{})
+	}
+
+	for _, hook := range hooks {
+		if !runHook(hook, logEvent) {
+			return false
+		}
+	}
+	for _, hook := range l.hooks {
+		if !runHook(hook, logEvent) {
+			return false
+		}
+	}
+	return true
+}
+
+// runHook runs a single hook, recovering from panics so a faulty hook cannot
+// take down the caller. A hook that panics is skipped and the event is kept.
+func runHook(hook ILogHook, logEvent *models.LogEvent) (keep bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			internalLog := common.NewLogger().WithContext("function", "arbor.runHook").GetLogger()
+			internalLog.Error().Str("hook", fmt.Sprintf("%T", hook)).Msgf("Log hook panicked: %v", r)
+			keep = true
+		}
+	}()
+
+	return hook.Run(logEvent)
+}
@@ -0,0 +1,142 @@
+package arbor
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/phuslu/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ternarybob/arbor/models"
+)
+
+// orderHook appends its name to the "order" field
+func orderHook(name string) ILogHook {
+	return LogHookFunc(func(event *models.LogEvent) bool {
+		order, _ := event.Fields["order"].(string)
+		event.Fields["order"] = order + name
+		return true
+	})
+}
+
+func TestHooks_MutateEvent(t *testing.T) {
+	emitter, capture := newCaptureLogger()
+
+	hooked := emitter.WithHook(LogHookFunc(func(event *models.LogEvent) bool {
+		event.Fields["service"] = "billing"
+		event.Message = strings.ToUpper(event.Message)
+		if event.Level == log.DebugLevel {
+			event.Level = log.InfoLevel
+		}
+		return true
+	}))
+
+	hooked.Debug().Msg("promoted")
+
+	events := capture.Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, "PROMOTED", events[0].Message)
+	assert.Equal(t, log.InfoLevel, events[0].Level)
+	assert.Equal(t, "billing", events[0].Fields["service"])
+}
+
+func TestHooks_Veto(t *testing.T) {
+	emitter, capture := newCaptureLogger()
+
+	var afterVeto atomic.Int32
+	hooked := emitter.
+		WithHook(LogHookFunc(func(event *models.LogEvent) bool {
+			return event.Message != "health check"
+		})).
+		WithHook(LogHookFunc(func(event *models.LogEvent) bool {
+			afterVeto.Add(1)
+			return true
+		}))
+
+	hooked.Info().Msg("health check")
+	hooked.Info().Msg("request")
+
+	events := capture.Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, "request", events[0].Message)
+	assert.EqualValues(t, 1, afterVeto.Load(), "hooks after a veto are not run")
+}
+
+func TestHooks_Order(t *testing.T) {
+	t.Cleanup(ClearHooks)
+	AddHook(orderHook("g1"))
+	AddHook(orderHook("g2"))
+
+	emitter, capture := newCaptureLogger()
+	parent := emitter.WithHook(orderHook("p"))
+	child := parent.WithHook(orderHook("c"))
+
+	child.Info().Msg("child")
+	parent.Info().Msg("parent")
+	emitter.Info().Msg("plain")
+
+	events := capture.Events()
+	require.Len(t, events, 3)
+	assert.Equal(t, "g1g2pc", events[0].Fields["order"])
+	assert.Equal(t, "g1g2p", events[1].Fields["order"], "hooks added to a fork do not affect the parent")
+	assert.Equal(t, "g1g2", events[2].Fields["order"])
+}
+
+func TestHooks_PanicIsRecovered(t *testing.T) {
+	emitter, capture := newCaptureLogger()
+
+	hooked := emitter.
+		WithHook(LogHookFunc(func(event *models.LogEvent) bool {
+			panic("faulty hook")
+		})).
+		WithHook(orderHook("next"))
+
+	assert.NotPanics(t, func() {
+		hooked.Info().Msg("survives")
+	})
+
+	events := capture.Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, "survives", events[0].Message)
+	assert.Equal(t, "next", events[0].Fields["order"], "hooks after a panicking hook still run")
+}
+
+func TestHooks_Concurrent(t *testing.T) {
+	t.Cleanup(ClearHooks)
+
+	var calls atomic.Int64
+	counting := LogHookFunc(func(event *models.LogEvent) bool {
+		calls.Add(1)
+		event.Fields["hooked"] = true
+		return true
+	})
+
+	emitter, capture := newCaptureLogger()
+	hooked := emitter.WithHook(counting)
+
+	const goroutines, perGoroutine = 8, 50
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perGoroutine; i++ {
+				// Registering global hooks while logging must be safe
+				if i == perGoroutine/2 {
+					AddHook(LogHookFunc(func(event *models.LogEvent) bool { return true }))
+				}
+				hooked.Info().Int("i", i).Msg("concurrent")
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.EqualValues(t, goroutines*perGoroutine, calls.Load())
+	events := capture.Events()
+	require.Len(t, events, goroutines*perGoroutine)
+	for _, event := range events {
+		assert.Equal(t, true, event.Fields["hooked"])
+	}
+}
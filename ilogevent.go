@@ -1,6 +1,11 @@
 package arbor
 
-import "time"
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+)
 
 // ILogEvent represents a fluent interface for building log events
 type ILogEvent interface {
@@ -13,6 +18,12 @@ type ILogEvent interface {
 	// Error field method
 	Err(err error) ILogEvent
 
+	// Stack records a stack trace, taken from the error when it carries one
+	Stack() ILogEvent
+
+	// Ctx attaches a context whose logger and correlation ID are applied to the event
+	Ctx(ctx context.Context) ILogEvent
+
 	// Message methods
 	Msg(message string)
 	Msgf(format string, args ...interface{})
@@ -37,4 +48,51 @@ type ILogEvent interface {
 
 	// Bool field method
 	Bool(key string, value bool) ILogEvent
+
+	// Any adds a field of any type; errors are stored as their message
+	Any(key string, value interface{}) ILogEvent
+
+	// Interface is an alias of Any
+	Interface(key string, value interface{}) ILogEvent
+
+	// Time field method
+	Time(key string, value time.Time) ILogEvent
+
+	// Uint64 field method
+	Uint64(key string, value uint64) ILogEvent
+
+	// Bytes adds a byte slice rendered as a string
+	Bytes(key string, value []byte) ILogEvent
+
+	// Hex adds a byte slice rendered as a hex string
+	Hex(key string, value []byte) ILogEvent
+
+	// Stringer adds the String() value of a fmt.Stringer
+	Stringer(key string, value fmt.Stringer) ILogEvent
+
+	// Integer slice field method
+	Ints(key string, values []int) ILogEvent
+
+	// Errs adds the messages of an error slice
+	Errs(key string, errs []error) ILogEvent
+
+	// IP address field method
+	IPAddr(key string, ip net.IP) ILogEvent
+
+	// Dict adds a nested object built with arbor.Dict()
+	Dict(key string, dict ILogEvent) ILogEvent
+
+	// Object adds a nested object whose fields are provided by an ILogObject
+	Object(key string, obj ILogObject) ILogEvent
+
+	// Func calls fn with the event, only if the event will be written
+	Func(fn func(event ILogEvent)) ILogEvent
+
+	// Lazy adds a field whose value is computed by fn, only if the event will be written
+	Lazy(key string, fn func() interface{}) ILogEvent
+}
+
+// ILogObject is implemented by types that add their own fields to a log event via ILogEvent.Object
+type ILogObject interface {
+	MarshalLogObject(event ILogEvent)
 }
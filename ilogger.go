@@ -38,24 +38,59 @@ type ILogger interface {
 	// ClearContext removes all context data from the logger
 	ClearContext() ILogger
 
+	// WithLevel returns a fork with its own minimum level; registered writers are not modified.
+	// Events below the level return a no-op ILogEvent.
 	WithLevel(lvl LogLevel) ILogger
 
-	// WithLevelFromString applies a log level from a string configuration
+	// WithLevelFromString returns a fork with its minimum level parsed from a string configuration
 	WithLevelFromString(levelStr string) ILogger
 
+	// WithContext returns a fork whose events all include the given string field
 	WithContext(key string, value string) ILogger
 
+	// WithFields returns a fork whose events all include the given fields
+	WithFields(fields map[string]interface{}) ILogger
+
+	// WithField returns a fork whose events all include the given field
+	WithField(key string, value interface{}) ILogger
+
+	// Typed variants of WithField
+	WithStr(key, value string) ILogger
+	WithInt(key string, value int) ILogger
+	WithInt64(key string, value int64) ILogger
+	WithFloat64(key string, value float64) ILogger
+	WithBool(key string, value bool) ILogger
+	WithDur(key string, value time.Duration) ILogger
+	WithTime(key string, value time.Time) ILogger
+
+	// WithCallerSkip returns a fork that skips additional stack frames when reporting the caller
+	WithCallerSkip(skip int) ILogger
+
+	// WithoutCaller returns a fork that does not capture the caller of each event
+	WithoutCaller() ILogger
+
+	// WithSampler returns a fork whose events are sampled, reporting dropped counts every reportInterval
+	WithSampler(sampler writers.ISampler, reportInterval time.Duration) ILogger
+
+	// WithHook returns a fork that runs hook on each event before it is written, after the global hooks
+	WithHook(hook ILogHook) ILogger
+
 	// Copy creates a forked copy of the logger with the same configuration and context.
 	// This supports tree-like logger usage where `With*` methods do not mutate the parent.
 	Copy() ILogger
 
+	// Start begins a span that logs when the operation starts and, with its duration, when it ends
+	Start(operation string) ISpan
+
 	// Fluent logging methods
 	Trace() ILogEvent
 	Debug() ILogEvent
 	Info() ILogEvent
 	Warn() ILogEvent
 	Error() ILogEvent
+	// Fatal events flush all writers and exit the process once written (see SetFatalBehavior)
 	Fatal() ILogEvent
+	// Panic events flush all writers and panic with the message once written
 	Panic() ILogEvent
 
 	GetMemoryLogs(correlationid string, minLevel LogLevel) (map[string]string, error)
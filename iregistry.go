@@ -5,7 +5,8 @@ import "github.com/ternarybob/arbor/writers"
 // NewWriterRegistry creates a new instance of WriterRegistry
 func NewWriterRegistry() IWriterRegistry {
 	return &WriterRegistry{
-		writers: make(map[string]writers.IWriter),
+		writers:    make(map[string]writers.IWriter),
+		generation: &writerGeneration{},
 	}
 }
 
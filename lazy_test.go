@@ -0,0 +1,112 @@
+package arbor
+
+import (
+	"testing"
+
+	"github.com/phuslu/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ternarybob/arbor/models"
+	"github.com/ternarybob/arbor/writers"
+)
+
+// leveledCaptureWriter is a captureWriter that reports a minimum level
+type leveledCaptureWriter struct {
+	captureWriter
+	level log.Level
+}
+
+func (lw *leveledCaptureWriter) GetLevel() log.Level { return lw.level }
+
+// lazyCounter counts how often a lazy value is computed
+type lazyCounter struct {
+	calls int
+}
+
+func (c *lazyCounter) value() interface{} {
+	c.calls++
+	return "expensive"
+}
+
+func TestLazy_EvaluatedWhenWritten(t *testing.T) {
+	emitter, capture := newCaptureLogger()
+	var counter lazyCounter
+
+	emitter.Debug().Lazy("payload", counter.value).Func(func(e ILogEvent) {
+		e.Int("size", 42)
+	}).Msg("sent")
+
+	assert.Equal(t, 1, counter.calls)
+	events := capture.Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, "expensive", events[0].Fields["payload"])
+	assert.EqualValues(t, 42, events[0].Fields["size"])
+}
+
+func TestLazy_SkippedBelowLoggerLevel(t *testing.T) {
+	emitter, capture := newCaptureLogger()
+	var counter lazyCounter
+	called := false
+
+	emitter.WithLevel(InfoLevel).Debug().Lazy("payload", counter.value).Func(func(ILogEvent) { called = true }).Msg("dropped")
+
+	assert.Zero(t, counter.calls)
+	assert.False(t, called)
+	assert.Empty(t, capture.Events())
+}
+
+func TestLazy_SkippedWhenNoWriterAccepts(t *testing.T) {
+	warn := &leveledCaptureWriter{level: log.WarnLevel}
+	errorOnly := &leveledCaptureWriter{level: log.ErrorLevel}
+	emitter := NewLogger().WithWriters([]writers.IWriter{warn, errorOnly})
+	var counter lazyCounter
+
+	emitter.Info().Lazy("payload", counter.value).Msg("rejected by every writer")
+	assert.Zero(t, counter.calls)
+
+	emitter.Warn().Lazy("payload", counter.value).Lazy("again", counter.value).Msg("accepted by one writer")
+	assert.Equal(t, 2, counter.calls)
+	require.Len(t, warn.Events(), 1)
+	assert.Equal(t, "accepted by one writer", warn.Events()[0].Message)
+}
+
+func TestLazy_GlobalRegistry(t *testing.T) {
+	isolateRegistry(t)
+	writer := &leveledCaptureWriter{level: log.ErrorLevel}
+	RegisterWriter("leveled", writer)
+	var counter lazyCounter
+
+	NewLogger().Warn().Lazy("payload", counter.value).Msg("rejected")
+	assert.Zero(t, counter.calls)
+
+	writer.level = log.WarnLevel
+	NewLogger().Warn().Lazy("payload", counter.value).Msg("accepted")
+	assert.Equal(t, 1, counter.calls)
+	assert.Len(t, writer.Events(), 1)
+}
+
+func TestLazy_HooksMayRaiseTheLevel(t *testing.T) {
+	writer := &leveledCaptureWriter{level: log.ErrorLevel}
+	escalate := LogHookFunc(func(event *models.LogEvent) bool {
+		event.Level = log.ErrorLevel
+		return true
+	})
+	emitter := NewLogger().WithWriters([]writers.IWriter{writer}).WithHook(escalate)
+	var counter lazyCounter
+
+	emitter.Info().Lazy("payload", counter.value).Msg("escalated")
+
+	assert.Equal(t, 1, counter.calls, "with hooks installed, lazy fields are computed")
+	require.Len(t, writer.Events(), 1)
+	assert.Equal(t, "expensive", writer.Events()[0].Fields["payload"])
+}
+
+func TestLazy_Dict(t *testing.T) {
+	emitter, capture := newCaptureLogger()
+
+	emitter.Info().Dict("request", Dict().Lazy("body", func() interface{} { return "{}" })).Msg("nested")
+
+	events := capture.Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, map[string]interface{}{"body": "{}"}, events[0].Fields["request"])
+}
@@ -0,0 +1,271 @@
+package arbor
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/phuslu/log"
+	"github.com/ternarybob/arbor/common"
+	"github.com/ternarybob/arbor/writers"
+)
+
+// WriterLevel describes the level of a registered writer, as returned by LevelHandler
+type WriterLevel struct {
+	Name     string     `json:"name"`
+	Level    string     `json:"level"`              // Empty if the writer does not report its level
+	RevertTo string     `json:"revertTo,omitempty"` // Level restored when the auto-revert timer fires
+	RevertAt *time.Time `json:"revertAt,omitempty"`
+}
+
+// levelRequest is the body of a PUT request
+type levelRequest struct {
+	Level       string `json:"level"`
+	RevertAfter string `json:"revertAfter"` // Duration such as "15m", or a number of minutes
+}
+
+// levelRevert is a pending auto-revert of a writer's level
+type levelRevert struct {
+	level log.Level
+	at    time.Time
+	timer *time.Timer
+}
+
+// levelHandler serves runtime level control for the writers of a registry
+type levelHandler struct {
+	registry IWriterRegistry
+	mu       sync.Mutex
+	reverts  map[string]*levelRevert
+}
+
+// LevelHandler returns an http.Handler that reads and changes the levels of the writers in the
+// global registry at runtime. Mount it under a prefix with http.StripPrefix:
+//
+//	mux.Handle("/debug/log/", http.StripPrefix("/debug/log", arbor.LevelHandler()))
+//
+//	GET /debug/log/          lists every writer and its level
+//	GET /debug/log/console   returns the level of the console writer
+//	PUT /debug/log/console   sets it: {"level":"debug","revertAfter":"15m"}
+//
+// "disabled" and "off" are rejected, as writers cannot be disabled by level.
+// The level and revertAfter values may also be given as query parameters (?level=debug&revert=15).
+// A revertAfter duration restores the previous level when it expires; a plain number is minutes.
+// The handler performs no authentication, so only expose it on an internal or protected route.
+func LevelHandler() http.Handler {
+	return NewLevelHandler(globalWriterRegistry)
+}
+
+// NewLevelHandler returns a level control handler for the writers of the given registry
+func NewLevelHandler(registry IWriterRegistry) http.Handler {
+	return &levelHandler{
+		registry: registry,
+		reverts:  make(map[string]*levelRevert),
+	}
+}
+
+func (h *levelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := strings.Trim(r.URL.Path, "/")
+
+	if name == "" {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			writeLevelError(w, http.StatusMethodNotAllowed, "method %s not allowed", r.Method)
+			return
+		}
+		writeLevelJSON(w, http.StatusOK, h.list())
+		return
+	}
+
+	writer := h.registry.GetRegisteredWriter(name)
+	if writer == nil {
+		writeLevelError(w, http.StatusNotFound, "writer '%s' is not registered", name)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		writeLevelJSON(w, http.StatusOK, h.describe(name, writer))
+	case http.MethodPut:
+		h.put(w, r, name, writer)
+	default:
+		w.Header().Set("Allow", "GET, HEAD, PUT")
+		writeLevelError(w, http.StatusMethodNotAllowed, "method %s not allowed", r.Method)
+	}
+}
+
+// put parses and applies a level change
+func (h *levelHandler) put(w http.ResponseWriter, r *http.Request, name string, writer writers.IWriter) {
+	var request levelRequest
+	if r.Body != nil && r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeLevelError(w, http.StatusBadRequest, "invalid request body: %v", err)
+			return
+		}
+	}
+	if request.Level == "" {
+		request.Level = r.URL.Query().Get("level")
+	}
+	if request.RevertAfter == "" {
+		request.RevertAfter = r.URL.Query().Get("revert")
+	}
+
+	level, err := ParseLevelString(request.Level)
+	if err != nil {
+		writeLevelError(w, http.StatusBadRequest, "invalid level '%s'", request.Level)
+		return
+	}
+	if level > log.PanicLevel {
+		// Writer levels are stored as a LogLevel, which has no disabled value
+		writeLevelError(w, http.StatusBadRequest, "level '%s' cannot be set on a writer; unregister it instead", request.Level)
+		return
+	}
+
+	revertAfter, err := parseRevertAfter(request.RevertAfter)
+	if err != nil {
+		writeLevelError(w, http.StatusBadRequest, "invalid revertAfter '%s': %v", request.RevertAfter, err)
+		return
+	}
+
+	if err := h.setLevel(name, writer, level, revertAfter); err != nil {
+		writeLevelError(w, http.StatusConflict, "%v", err)
+		return
+	}
+
+	internalLog := common.NewLogger().WithContext("function", "LevelHandler.put").GetLogger()
+	internalLog.Info().Msgf("Writer '%s' level set to %s (revert after %v)", name, levelName(level), revertAfter)
+
+	writeLevelJSON(w, http.StatusOK, h.describe(name, writer))
+}
+
+// setLevel changes the writer's level, replacing any pending auto-revert. When revertAfter is set,
+// the level in force before the first temporary change is restored once it expires.
+func (h *levelHandler) setLevel(name string, writer writers.IWriter, level log.Level, revertAfter time.Duration) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	pending := h.reverts[name]
+
+	if revertAfter > 0 {
+		original := writers.WriterLevel(writer)
+		if pending != nil {
+			original = pending.level
+		}
+		if original == 0 {
+			return fmt.Errorf("writer '%s' does not report its level, so it cannot be reverted", name)
+		}
+
+		revert := &levelRevert{level: original, at: time.Now().Add(revertAfter)}
+		revert.timer = time.AfterFunc(revertAfter, func() { h.revert(name, revert) })
+		h.reverts[name] = revert
+	} else {
+		delete(h.reverts, name)
+	}
+
+	if pending != nil {
+		pending.timer.Stop()
+	}
+
+	writer.WithLevel(level)
+	return nil
+}
+
+// revert restores the level saved by setLevel, unless it has since been replaced
+func (h *levelHandler) revert(name string, revert *levelRevert) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.reverts[name] != revert {
+		return
+	}
+	delete(h.reverts, name)
+
+	if writer := h.registry.GetRegisteredWriter(name); writer != nil {
+		writer.WithLevel(revert.level)
+
+		internalLog := common.NewLogger().WithContext("function", "LevelHandler.revert").GetLogger()
+		internalLog.Info().Msgf("Writer '%s' level reverted to %s", name, levelName(revert.level))
+	}
+}
+
+// list describes every registered writer, sorted by name
+func (h *levelHandler) list() []WriterLevel {
+	registered := h.registry.GetAllRegisteredWriters()
+	names := make([]string, 0, len(registered))
+	for name := range registered {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	levels := make([]WriterLevel, 0, len(names))
+	for _, name := range names {
+		levels = append(levels, h.describe(name, registered[name]))
+	}
+	return levels
+}
+
+func (h *levelHandler) describe(name string, writer writers.IWriter) WriterLevel {
+	described := WriterLevel{
+		Name:  name,
+		Level: levelName(writers.WriterLevel(writer)),
+	}
+
+	h.mu.Lock()
+	if revert, exists := h.reverts[name]; exists {
+		at := revert.at
+		described.RevertTo = levelName(revert.level)
+		described.RevertAt = &at
+	}
+	h.mu.Unlock()
+
+	return described
+}
+
+// levelName renders a level for the level handler; unknown levels are empty
+func levelName(level log.Level) string {
+	switch {
+	case level == 0:
+		return ""
+	case level > log.PanicLevel:
+		return "disabled"
+	default:
+		return LevelToString(level)
+	}
+}
+
+// parseRevertAfter parses a duration such as "15m"; a plain number is a count of minutes
+func parseRevertAfter(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	if minutes, err := strconv.Atoi(value); err == nil {
+		if minutes < 0 {
+			return 0, fmt.Errorf("must not be negative")
+		}
+		return time.Duration(minutes) * time.Minute, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if duration < 0 {
+		return 0, fmt.Errorf("must not be negative")
+	}
+	return duration, nil
+}
+
+func writeLevelJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+func writeLevelError(w http.ResponseWriter, status int, format string, args ...interface{}) {
+	writeLevelJSON(w, status, map[string]string{"error": fmt.Sprintf(format, args...)})
+}
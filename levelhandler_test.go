@@ -0,0 +1,164 @@
+package arbor
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/phuslu/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ternarybob/arbor/levels"
+	"github.com/ternarybob/arbor/models"
+	"github.com/ternarybob/arbor/writers"
+)
+
+func newLevelTestHandler() (http.Handler, IWriterRegistry) {
+	registry := NewWriterRegistry()
+	registry.RegisterWriter(WRITER_CONSOLE, writers.ConsoleWriter(models.WriterConfiguration{
+		Type:  models.LogWriterTypeConsole,
+		Level: levels.InfoLevel,
+	}))
+	registry.RegisterWriter("capture", &captureWriter{})
+	return NewLevelHandler(registry), registry
+}
+
+func serveLevel(t *testing.T, handler http.Handler, method, target, body string) (*httptest.ResponseRecorder, WriterLevel) {
+	t.Helper()
+	request := httptest.NewRequest(method, target, strings.NewReader(body))
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	var described WriterLevel
+	if recorder.Code == http.StatusOK && !strings.HasPrefix(recorder.Body.String(), "[") {
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &described))
+	}
+	return recorder, described
+}
+
+func consoleLevel(registry IWriterRegistry) log.Level {
+	return registry.GetRegisteredWriter(WRITER_CONSOLE).(writers.ILevelReporter).GetLevel()
+}
+
+func TestLevelHandler_List(t *testing.T) {
+	handler, _ := newLevelTestHandler()
+
+	recorder, _ := serveLevel(t, handler, http.MethodGet, "/", "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+	var listed []WriterLevel
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &listed))
+	assert.Equal(t, []WriterLevel{
+		{Name: "capture", Level: ""},
+		{Name: WRITER_CONSOLE, Level: "info"},
+	}, listed)
+}
+
+func TestLevelHandler_GetAndPut(t *testing.T) {
+	handler, registry := newLevelTestHandler()
+
+	recorder, described := serveLevel(t, handler, http.MethodGet, "/console", "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "info", described.Level)
+
+	recorder, described = serveLevel(t, handler, http.MethodPut, "/console", `{"level":"debug"}`)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.Equal(t, "debug", described.Level)
+	assert.Nil(t, described.RevertAt)
+	assert.Equal(t, log.DebugLevel, consoleLevel(registry))
+
+	recorder, described = serveLevel(t, handler, http.MethodPut, "/console/?level=warn", "")
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.Equal(t, "warn", described.Level)
+}
+
+func TestLevelHandler_Errors(t *testing.T) {
+	handler, _ := newLevelTestHandler()
+
+	recorder, _ := serveLevel(t, handler, http.MethodGet, "/missing", "")
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "missing")
+
+	recorder, _ = serveLevel(t, handler, http.MethodPut, "/console", `{"level":"loud"}`)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder, _ = serveLevel(t, handler, http.MethodPut, "/console", `{"level":"debug","revertAfter":"soon"}`)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder, _ = serveLevel(t, handler, http.MethodPut, "/console", `not json`)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder, _ = serveLevel(t, handler, http.MethodDelete, "/console", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+	assert.Equal(t, "GET, HEAD, PUT", recorder.Header().Get("Allow"))
+
+	recorder, _ = serveLevel(t, handler, http.MethodPut, "/capture", `{"level":"debug","revertAfter":"1m"}`)
+	assert.Equal(t, http.StatusConflict, recorder.Code, "writers that do not report a level cannot be reverted")
+}
+
+func TestLevelHandler_RejectsDisabled(t *testing.T) {
+	handler, registry := newLevelTestHandler()
+
+	for _, body := range []string{`{"level":"disabled"}`, `{"level":"off"}`} {
+		recorder, _ := serveLevel(t, handler, http.MethodPut, "/console", body)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, body)
+	}
+
+	recorder, described := serveLevel(t, handler, http.MethodGet, "/console", "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "info", described.Level, "a rejected level leaves the writer unchanged")
+	assert.Equal(t, log.InfoLevel, consoleLevel(registry))
+}
+
+func TestLevelHandler_AutoRevert(t *testing.T) {
+	handler, registry := newLevelTestHandler()
+
+	recorder, described := serveLevel(t, handler, http.MethodPut, "/console", `{"level":"trace","revertAfter":"50ms"}`)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.Equal(t, "trace", described.Level)
+	assert.Equal(t, "info", described.RevertTo)
+	require.NotNil(t, described.RevertAt)
+
+	// A second temporary change keeps the original level as the revert target
+	_, described = serveLevel(t, handler, http.MethodPut, "/console", `{"level":"debug","revertAfter":"50ms"}`)
+	assert.Equal(t, "info", described.RevertTo)
+
+	assert.Eventually(t, func() bool {
+		return consoleLevel(registry) == log.InfoLevel
+	}, time.Second, 10*time.Millisecond)
+
+	_, described = serveLevel(t, handler, http.MethodGet, "/console", "")
+	assert.Empty(t, described.RevertTo)
+	assert.Nil(t, described.RevertAt)
+}
+
+func TestLevelHandler_PermanentChangeCancelsRevert(t *testing.T) {
+	handler, registry := newLevelTestHandler()
+
+	serveLevel(t, handler, http.MethodPut, "/console", `{"level":"debug","revertAfter":"30ms"}`)
+	serveLevel(t, handler, http.MethodPut, "/console", `{"level":"error"}`)
+
+	time.Sleep(80 * time.Millisecond)
+	assert.Equal(t, log.ErrorLevel, consoleLevel(registry))
+}
+
+func TestParseRevertAfter(t *testing.T) {
+	duration, err := parseRevertAfter("15")
+	require.NoError(t, err)
+	assert.Equal(t, 15*time.Minute, duration)
+
+	duration, err = parseRevertAfter("90s")
+	require.NoError(t, err)
+	assert.Equal(t, 90*time.Second, duration)
+
+	duration, err = parseRevertAfter("")
+	require.NoError(t, err)
+	assert.Zero(t, duration)
+
+	_, err = parseRevertAfter("-5")
+	assert.Error(t, err)
+}
@@ -1,6 +1,7 @@
 package levels
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -112,3 +113,33 @@ func FromLogLevel(l log.Level) LogLevel {
 		return InfoLevel
 	}
 }
+
+// UnmarshalJSON accepts a level name such as "debug" (see ParseLevelString) or its numeric value.
+// "disabled", "off" and 0 are rejected, since writers cannot be disabled by level.
+func (l *LogLevel) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		var value uint32
+		if err := json.Unmarshal(data, &value); err != nil {
+			return fmt.Errorf("level must be a name such as \"info\" or a number, got %s", data)
+		}
+		if value == uint32(Disabled) {
+			return fmt.Errorf("level %d (disabled) cannot be used here", value)
+		}
+		if value > uint32(PanicLevel) {
+			return fmt.Errorf("unknown level: %d", value)
+		}
+		*l = LogLevel(value)
+		return nil
+	}
+
+	level, err := ParseLevelString(name)
+	if err != nil {
+		return err
+	}
+	if level > log.PanicLevel {
+		return fmt.Errorf("level %q cannot be used here", name)
+	}
+	*l = FromLogLevel(level)
+	return nil
+}
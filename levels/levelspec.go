@@ -0,0 +1,116 @@
+package levels
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/phuslu/log"
+)
+
+// LevelSpec is a default level with per-prefix overrides, parsed from a spec such as
+// "info,db=debug,http=warn,gin=error"
+type LevelSpec struct {
+	Default  log.Level            // 0 when the spec has no default entry
+	Prefixes map[string]log.Level // Keyed by lower-cased prefix
+}
+
+// ParseLevelSpec parses a comma-separated level spec. Each entry is either a bare level, which
+// sets the default, or prefix=level. Levels follow the rules of ParseLevelString; prefixes are
+// case-insensitive and "*" is an alias for the default. Empty entries are ignored.
+func ParseLevelSpec(spec string) (*LevelSpec, error) {
+	parsed := &LevelSpec{Prefixes: make(map[string]log.Level)}
+	hasDefault := false
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		prefix, levelStr, isPrefixed := strings.Cut(entry, "=")
+		prefix = strings.ToLower(strings.TrimSpace(prefix))
+		levelStr = strings.TrimSpace(levelStr)
+
+		if !isPrefixed || prefix == "*" {
+			if !isPrefixed {
+				levelStr = prefix
+			}
+			if hasDefault {
+				return nil, fmt.Errorf("level spec %q: more than one default level", spec)
+			}
+			level, err := ParseLevelString(levelStr)
+			if err != nil {
+				return nil, fmt.Errorf("level spec %q: %w", spec, err)
+			}
+			parsed.Default = level
+			hasDefault = true
+			continue
+		}
+
+		if prefix == "" {
+			return nil, fmt.Errorf("level spec %q: empty prefix in %q", spec, entry)
+		}
+		if _, exists := parsed.Prefixes[prefix]; exists {
+			return nil, fmt.Errorf("level spec %q: duplicate prefix %q", spec, prefix)
+		}
+		level, err := ParseLevelString(levelStr)
+		if err != nil {
+			return nil, fmt.Errorf("level spec %q: prefix %q: %w", spec, prefix, err)
+		}
+		parsed.Prefixes[prefix] = level
+	}
+
+	return parsed, nil
+}
+
+// PrefixLevel returns the level for a prefix. A prefix without its own entry inherits the entry
+// of its nearest dotted parent, so "db" also applies to "db.pool".
+func (s *LevelSpec) PrefixLevel(prefix string) (log.Level, bool) {
+	if s == nil || len(s.Prefixes) == 0 || prefix == "" {
+		return 0, false
+	}
+
+	prefix = strings.ToLower(prefix)
+	for {
+		if level, exists := s.Prefixes[prefix]; exists {
+			return level, true
+		}
+		dot := strings.LastIndexByte(prefix, '.')
+		if dot < 0 {
+			return 0, false
+		}
+		prefix = prefix[:dot]
+	}
+}
+
+// String returns the spec in canonical form: the default first, then prefixes in sorted order
+func (s *LevelSpec) String() string {
+	if s == nil {
+		return ""
+	}
+
+	entries := make([]string, 0, len(s.Prefixes)+1)
+	if s.Default != 0 {
+		entries = append(entries, specLevelName(s.Default))
+	}
+
+	prefixes := make([]string, 0, len(s.Prefixes))
+	for prefix := range s.Prefixes {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+	for _, prefix := range prefixes {
+		entries = append(entries, prefix+"="+specLevelName(s.Prefixes[prefix]))
+	}
+
+	return strings.Join(entries, ",")
+}
+
+// specLevelName renders a level so that ParseLevelString parses it back
+func specLevelName(level log.Level) string {
+	if level > log.PanicLevel {
+		return "disabled"
+	}
+	return level.String()
+}
@@ -1,6 +1,7 @@
 package arbor
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/phuslu/log"
@@ -227,3 +228,42 @@ func TestLogLevel_DefaultBehavior(t *testing.T) {
 		})
 	}
 }
+
+func TestLogLevel_UnmarshalJSON(t *testing.T) {
+	testCases := []struct {
+		input       string
+		expected    LogLevel
+		expectError bool
+	}{
+		{`"debug"`, DebugLevel, false},
+		{`"WARN"`, WarnLevel, false},
+		{`3`, InfoLevel, false},
+		{`7`, PanicLevel, false},
+		{`"disabled"`, 0, true},
+		{`"off"`, 0, true},
+		{`0`, 0, true},
+		{`8`, 0, true},
+		{`-1`, 0, true},
+		{`"loud"`, 0, true},
+		{`true`, 0, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			var level LogLevel
+			err := json.Unmarshal([]byte(tc.input), &level)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected error for %s, got level %d", tc.input, level)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Unexpected error for %s: %v", tc.input, err)
+			}
+			if level != tc.expected {
+				t.Errorf("Expected level %d for %s, got %d", tc.expected, tc.input, level)
+			}
+		})
+	}
+}
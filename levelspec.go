@@ -0,0 +1,55 @@
+package arbor
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"github.com/phuslu/log"
+	"github.com/ternarybob/arbor/levels"
+)
+
+// activeLevelSpec holds the per-prefix level overrides set with SetLevelSpec
+var activeLevelSpec atomic.Pointer[levels.LevelSpec]
+
+// SetLevelSpec sets per-prefix level overrides for every logger, e.g. "info,db=debug,http=warn".
+// It can be called at any time; loggers pick up the new spec on their next event.
+// An empty spec removes all overrides. On error the current spec is kept.
+//
+// A logger whose prefix (see WithPrefix) matches an entry uses that level, overriding its own
+// WithLevel; other loggers use their own level, or the spec's default if they have none.
+// Writers still apply their own levels, so set them to the most verbose level the spec may enable.
+func SetLevelSpec(spec string) error {
+	if strings.TrimSpace(spec) == "" {
+		activeLevelSpec.Store(nil)
+		return nil
+	}
+
+	parsed, err := levels.ParseLevelSpec(spec)
+	if err != nil {
+		return err
+	}
+	activeLevelSpec.Store(parsed)
+	return nil
+}
+
+// GetLevelSpec returns the active level spec in canonical form, or "" if none is set
+func GetLevelSpec() string {
+	return activeLevelSpec.Load().String()
+}
+
+// threshold returns the minimum level for the logger's events: a matching prefix entry of the
+// level spec, then the logger's own level, then the spec's default. 0 means no threshold.
+func (l *logger) threshold() log.Level {
+	spec := activeLevelSpec.Load()
+	if spec == nil {
+		return l.level
+	}
+
+	if level, exists := spec.PrefixLevel(l.contextData[PREFIX_KEY]); exists {
+		return level
+	}
+	if l.level != 0 {
+		return l.level
+	}
+	return spec.Default
+}
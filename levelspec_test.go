@@ -0,0 +1,122 @@
+package arbor
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/phuslu/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ternarybob/arbor/levels"
+)
+
+func TestParseLevelSpec(t *testing.T) {
+	spec, err := levels.ParseLevelSpec(" info, DB=Debug ,http=warning,,gin=off ")
+	require.NoError(t, err)
+
+	assert.Equal(t, log.InfoLevel, spec.Default)
+	assert.Equal(t, map[string]log.Level{
+		"db":   log.DebugLevel,
+		"http": log.WarnLevel,
+		"gin":  log.PanicLevel + 1,
+	}, spec.Prefixes)
+	assert.Equal(t, "info,db=debug,gin=disabled,http=warn", spec.String())
+
+	level, exists := spec.PrefixLevel("DB.pool")
+	assert.True(t, exists, "dotted prefixes inherit their parent's entry")
+	assert.Equal(t, log.DebugLevel, level)
+
+	_, exists = spec.PrefixLevel("dbx")
+	assert.False(t, exists)
+
+	spec, err = levels.ParseLevelSpec("*=error,db=trace")
+	require.NoError(t, err)
+	assert.Equal(t, log.ErrorLevel, spec.Default)
+}
+
+func TestParseLevelSpec_Errors(t *testing.T) {
+	for _, spec := range []string{
+		"loud",
+		"info,db=loud",
+		"info,warn",
+		"db=debug,db=info",
+		"=debug",
+	} {
+		_, err := levels.ParseLevelSpec(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestSetLevelSpec(t *testing.T) {
+	t.Cleanup(func() { SetLevelSpec("") })
+	emitter, capture := newCaptureLogger()
+
+	db := emitter.WithPrefix("db")
+	pool := emitter.WithPrefix("db.pool")
+	http := emitter.WithPrefix("http")
+	plain := emitter
+
+	require.NoError(t, SetLevelSpec("info,db=debug,http=warn"))
+	assert.Equal(t, "info,db=debug,http=warn", GetLevelSpec())
+
+	db.Debug().Msg("db debug")
+	pool.Debug().Msg("pool debug")
+	http.Info().Msg("http info")
+	http.Warn().Msg("http warn")
+	plain.Debug().Msg("plain debug")
+	plain.Info().Msg("plain info")
+
+	var messages []string
+	for _, event := range capture.Events() {
+		messages = append(messages, event.Message)
+	}
+	assert.Equal(t, []string{"db debug", "pool debug", "http warn", "plain info"}, messages)
+}
+
+func TestSetLevelSpec_HotUpdate(t *testing.T) {
+	t.Cleanup(func() { SetLevelSpec("") })
+	emitter, capture := newCaptureLogger()
+	db := emitter.WithPrefix("db")
+
+	require.NoError(t, SetLevelSpec("db=error"))
+	db.Info().Msg("suppressed")
+
+	require.NoError(t, SetLevelSpec("db=trace"))
+	db.Trace().Msg("enabled")
+
+	assert.Error(t, SetLevelSpec("db=loud"))
+	assert.Equal(t, "db=trace", GetLevelSpec(), "an invalid spec keeps the current one")
+
+	require.NoError(t, SetLevelSpec(""))
+	assert.Empty(t, GetLevelSpec())
+	db.Trace().Msg("no threshold")
+
+	events := capture.Events()
+	require.Len(t, events, 2)
+	assert.Equal(t, "enabled", events[0].Message)
+	assert.Equal(t, "no threshold", events[1].Message)
+}
+
+func TestSetLevelSpec_Precedence(t *testing.T) {
+	t.Cleanup(func() { SetLevelSpec("") })
+	emitter, capture := newCaptureLogger()
+
+	require.NoError(t, SetLevelSpec("warn,db=debug"))
+
+	emitter.WithLevel(DebugLevel).Debug().Msg("logger level beats the default")
+	emitter.WithPrefix("db").WithLevel(ErrorLevel).Debug().Msg("prefix entry beats the logger level")
+	emitter.Info().Msg("default applies")
+
+	slogger := slog.New(NewSlogHandler(emitter.WithPrefix("db"), nil))
+	slogger.Debug("slog follows the prefix entry")
+
+	var messages []string
+	for _, event := range capture.Events() {
+		messages = append(messages, event.Message)
+	}
+	assert.Equal(t, []string{
+		"logger level beats the default",
+		"prefix entry beats the logger level",
+		"slog follows the prefix entry",
+	}, messages)
+}
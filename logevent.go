@@ -1,12 +1,13 @@
 package arbor
 
 import (
-	"encoding/json"
+	"context"
+	"encoding/hex"
 	"fmt"
+	"net"
 	"time"
 
 	"github.com/phuslu/log"
-	"github.com/ternarybob/arbor/models"
 )
 
 // logEvent implements the ILogEvent interface
@@ -15,8 +16,20 @@ type logEvent struct {
 	level  log.Level
 	fields map[string]interface{}
 	err    error
+	stack  bool
+	ctx    context.Context
+	accept eventAcceptance
 }
 
+// eventAcceptance caches whether an event will be written, checked at most once per event
+type eventAcceptance uint8
+
+const (
+	acceptUnknown eventAcceptance = iota
+	acceptYes
+	acceptNo
+)
+
 // newLogEvent creates a new log event
 func newLogEvent(logger *logger, level log.Level) *logEvent {
 	return &logEvent{
@@ -38,12 +51,28 @@ func (le *logEvent) Str(key, value string) ILogEvent {
 	return le
 }
 
-// Err adds an error field to the log event
+// Err adds an error field to the log event.
+// The error's message is recorded along with the chain of errors it wraps.
 func (le *logEvent) Err(err error) ILogEvent {
 	le.err = err
 	return le
 }
 
+// Stack records a stack trace with the event. If the error set with Err carries
+// a pkg/errors-style stack trace, that trace is used; otherwise the stack of the
+// logging call site is captured.
+func (le *logEvent) Stack() ILogEvent {
+	le.stack = true
+	return le
+}
+
+// Ctx attaches a context to the log event. The correlation ID, prefix and context
+// fields carried by the context (see NewContext and ContextWithCorrelationID) are applied when the event is written.
+func (le *logEvent) Ctx(ctx context.Context) ILogEvent {
+	le.ctx = ctx
+	return le
+}
+
 // Int adds an integer field to the log event
 func (le *logEvent) Int(key string, value int) ILogEvent {
 	le.fields[key] = value
@@ -86,6 +115,143 @@ func (le *logEvent) Bool(key string, value bool) ILogEvent {
 	return le
 }
 
+// Any adds a field of any type to the log event.
+// Errors are stored as their message; other values are stored as-is and rendered by each writer.
+func (le *logEvent) Any(key string, value interface{}) ILogEvent {
+	if err, ok := value.(error); ok && err != nil {
+		le.fields[key] = err.Error()
+		return le
+	}
+	le.fields[key] = value
+	return le
+}
+
+// Interface adds a field of any type to the log event (alias of Any)
+func (le *logEvent) Interface(key string, value interface{}) ILogEvent {
+	return le.Any(key, value)
+}
+
+// Time adds a time field to the log event
+func (le *logEvent) Time(key string, value time.Time) ILogEvent {
+	le.fields[key] = value
+	return le
+}
+
+// Uint64 adds a uint64 field to the log event
+func (le *logEvent) Uint64(key string, value uint64) ILogEvent {
+	le.fields[key] = value
+	return le
+}
+
+// Bytes adds a byte slice field to the log event, rendered as a string
+func (le *logEvent) Bytes(key string, value []byte) ILogEvent {
+	le.fields[key] = string(value)
+	return le
+}
+
+// Hex adds a byte slice field to the log event, rendered as a hex string
+func (le *logEvent) Hex(key string, value []byte) ILogEvent {
+	le.fields[key] = hex.EncodeToString(value)
+	return le
+}
+
+// Stringer adds the String() value of a fmt.Stringer to the log event
+func (le *logEvent) Stringer(key string, value fmt.Stringer) ILogEvent {
+	if value == nil {
+		le.fields[key] = nil
+		return le
+	}
+	le.fields[key] = value.String()
+	return le
+}
+
+// Ints adds an integer slice field to the log event
+func (le *logEvent) Ints(key string, values []int) ILogEvent {
+	le.fields[key] = append([]int(nil), values...)
+	return le
+}
+
+// Errs adds the messages of an error slice to the log event; nil errors are skipped
+func (le *logEvent) Errs(key string, errs []error) ILogEvent {
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		if err != nil {
+			messages = append(messages, err.Error())
+		}
+	}
+	le.fields[key] = messages
+	return le
+}
+
+// IPAddr adds an IP address field to the log event
+func (le *logEvent) IPAddr(key string, ip net.IP) ILogEvent {
+	le.fields[key] = ip.String()
+	return le
+}
+
+// Dict adds a nested object built with arbor.Dict() to the log event
+func (le *logEvent) Dict(key string, dict ILogEvent) ILogEvent {
+	if nested, ok := dict.(*logEvent); ok {
+		le.fields[key] = nested.fields
+	}
+	return le
+}
+
+// Object adds a nested object whose fields are provided by an ILogObject
+func (le *logEvent) Object(key string, obj ILogObject) ILogEvent {
+	if obj == nil {
+		le.fields[key] = nil
+		return le
+	}
+	nested := newLogEvent(nil, 0)
+	obj.MarshalLogObject(nested)
+	le.fields[key] = nested.fields
+	return le
+}
+
+// Func calls fn with the event, only if the event will be written: the logger's level allows it
+// and at least one writer accepts its level. Use it to build several expensive fields at once.
+//
+// Example:
+//
+//	logger.Debug().Func(func(e arbor.ILogEvent) {
+//		e.Str("body", string(dumpRequest(r))).Int("headers", len(r.Header))
+//	}).Msg("Request")
+func (le *logEvent) Func(fn func(event ILogEvent)) ILogEvent {
+	if fn != nil && le.accepted() {
+		fn(le)
+	}
+	return le
+}
+
+// Lazy adds a field whose value is computed by fn, only if the event will be written.
+// The value is stored as with Any.
+//
+// Example:
+//
+//	logger.Debug().Lazy("payload", func() interface{} { return mustJSON(payload) }).Msg("Sending")
+func (le *logEvent) Lazy(key string, fn func() interface{}) ILogEvent {
+	if fn != nil && le.accepted() {
+		le.Any(key, fn())
+	}
+	return le
+}
+
+// accepted reports whether the event will be written, checking the writers on first use.
+// Field containers created with Dict() are always accepted; their parent event decides.
+func (le *logEvent) accepted() bool {
+	if le.logger == nil {
+		return true
+	}
+	if le.accept == acceptUnknown {
+		le.accept = acceptNo
+		if le.logger.accepts(le.level) {
+			le.accept = acceptYes
+		}
+	}
+	return le.accept == acceptYes
+}
+
 // Msg logs the message with the accumulated fields
 func (le *logEvent) Msg(message string) {
 	le.writeLog(message)
@@ -97,53 +263,99 @@ func (le *logEvent) Msgf(format string, args ...interface{}) {
 	le.writeLog(message)
 }
 
+// callerDepth is the number of arbor frames (writeLog and Msg/Msgf) between
+// writeLog and the logging call site
+const callerDepth = 2
+
 // writeLog writes the log event to all configured writers
 func (le *logEvent) writeLog(message string) {
-	// Create a log event model
-	logEvent := &models.LogEvent{
-		Level:     le.level,
-		Timestamp: time.Now(),
-		Message:   message,
-		Fields:    le.fields,
+	// Field containers created with Dict() have no logger and are never written
+	if le.logger == nil {
+		return
 	}
 
-	// Add error if present
-	if le.err != nil {
-		logEvent.Error = le.err.Error()
+	// Skip building an event that no writer will accept
+	if !le.accepted() {
+		le.logger.terminate(le.level, message)
+		return
 	}
 
-	// Add correlation ID if present
-	if correlationID, exists := le.logger.contextData[CORRELATION_ID_KEY]; exists {
-		logEvent.CorrelationID = correlationID
-	}
+	// Create a log event model with the logger's and the attached context's values
+	logEvent := le.logger.buildEvent(le.ctx, le.level, message, le.fields)
 
-	// Add prefix if present
-	if prefix, exists := le.logger.contextData[PREFIX_KEY]; exists {
-		logEvent.Prefix = prefix
+	// Add error and the chain of errors it wraps if present
+	if le.err != nil {
+		logEvent.Error = le.err.Error()
+		logEvent.ErrorChain = errorChain(le.err)
 	}
 
-	// Add function name
-	logEvent.Function = le.logger.getFunctionName()
-
-	// Marshal once and send to all writers
-	jsonData, err := json.Marshal(logEvent)
-	if err != nil {
-		return // Or handle error appropriately
+	// Add stack trace if requested, preferring one carried by the error.
+	// Skips writeLog and Msg/Msgf so the trace starts at the logging call site.
+	if le.stack {
+		logEvent.Stack = errorStack(le.err)
+		if len(logEvent.Stack) == 0 {
+			logEvent.Stack = callerStack(callerDepth + le.logger.callerSkip)
+		}
 	}
 
-	// If the logger has its own writers, use them. Otherwise, use the global registry.
-	if le.logger.writers != nil {
-		for _, writer := range le.logger.writers {
-			writer.Write(jsonData)
-		}
-	} else {
-		registeredWriters := GetAllRegisteredWriters()
-		for _, writer := range registeredWriters {
-			writer.Write(jsonData)
+	// Add caller, skipping writeLog and Msg/Msgf
+	if !le.logger.noCaller {
+		if caller := callerInfo(callerDepth + le.logger.callerSkip); caller != nil {
+			logEvent.Caller = caller
+			logEvent.Function = caller.Function
 		}
 	}
+
+	le.logger.writeEvent(logEvent)
+	le.logger.terminate(le.level, message)
 }
 
+// Dict creates a field container for building nested objects with ILogEvent.Dict.
+// The returned event only collects fields; calling Msg on it writes nothing.
+//
+// Example:
+//
+//	logger.Info().Dict("request", arbor.Dict().Str("method", "GET").Int("status", 200)).Msg("Handled")
+func Dict() ILogEvent {
+	return newLogEvent(nil, 0)
+}
+
+// noopLogEvent is returned for events below the logger's level.
+// Every method is a no-op, so disabled log statements cost no allocations.
+type noopLogEvent struct{}
+
+// disabledEvent is the shared no-op event
+var disabledEvent ILogEvent = noopLogEvent{}
+
+func (ne noopLogEvent) Strs(key string, values []string) ILogEvent        { return ne }
+func (ne noopLogEvent) Str(key, value string) ILogEvent                   { return ne }
+func (ne noopLogEvent) Err(err error) ILogEvent                           { return ne }
+func (ne noopLogEvent) Stack() ILogEvent                                  { return ne }
+func (ne noopLogEvent) Ctx(ctx context.Context) ILogEvent                 { return ne }
+func (ne noopLogEvent) Msg(message string)                                {}
+func (ne noopLogEvent) Msgf(format string, args ...interface{})           {}
+func (ne noopLogEvent) Int(key string, value int) ILogEvent               { return ne }
+func (ne noopLogEvent) Int32(key string, value int32) ILogEvent           { return ne }
+func (ne noopLogEvent) Int64(key string, value int64) ILogEvent           { return ne }
+func (ne noopLogEvent) Float32(key string, value float32) ILogEvent       { return ne }
+func (ne noopLogEvent) Dur(key string, value time.Duration) ILogEvent     { return ne }
+func (ne noopLogEvent) Float64(key string, value float64) ILogEvent       { return ne }
+func (ne noopLogEvent) Bool(key string, value bool) ILogEvent             { return ne }
+func (ne noopLogEvent) Any(key string, value interface{}) ILogEvent       { return ne }
+func (ne noopLogEvent) Interface(key string, value interface{}) ILogEvent { return ne }
+func (ne noopLogEvent) Time(key string, value time.Time) ILogEvent        { return ne }
+func (ne noopLogEvent) Uint64(key string, value uint64) ILogEvent         { return ne }
+func (ne noopLogEvent) Bytes(key string, value []byte) ILogEvent          { return ne }
+func (ne noopLogEvent) Hex(key string, value []byte) ILogEvent            { return ne }
+func (ne noopLogEvent) Stringer(key string, value fmt.Stringer) ILogEvent { return ne }
+func (ne noopLogEvent) Ints(key string, values []int) ILogEvent           { return ne }
+func (ne noopLogEvent) Errs(key string, errs []error) ILogEvent           { return ne }
+func (ne noopLogEvent) IPAddr(key string, ip net.IP) ILogEvent            { return ne }
+func (ne noopLogEvent) Dict(key string, dict ILogEvent) ILogEvent         { return ne }
+func (ne noopLogEvent) Object(key string, obj ILogObject) ILogEvent       { return ne }
+func (ne noopLogEvent) Func(fn func(event ILogEvent)) ILogEvent           { return ne }
+func (ne noopLogEvent) Lazy(key string, fn func() interface{}) ILogEvent  { return ne }
+
 // LevelToString converts log level to string representation (exported for writers)
 func LevelToString(level log.Level) string {
 	switch level {
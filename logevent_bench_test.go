@@ -0,0 +1,98 @@
+package arbor
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ternarybob/arbor/levels"
+	"github.com/ternarybob/arbor/models"
+	"github.com/ternarybob/arbor/writers"
+)
+
+// legacyWriter hides WriteEvent so the wrapped writer is driven through the JSON Write path
+type legacyWriter struct {
+	writers.IWriter
+}
+
+// newBenchmarkWriters creates a file writer and two channel writers, the typical
+// fan-out of an API service (file output, memory store and a streaming channel).
+func newBenchmarkWriters(b *testing.B) []writers.IWriter {
+	b.Helper()
+
+	fileWriter := writers.FileWriter(models.WriterConfiguration{
+		Type:       models.LogWriterTypeFile,
+		Level:      levels.TraceLevel,
+		FileName:   filepath.Join(b.TempDir(), "bench.log"),
+		OutputType: models.OutputFormatJSON,
+	})
+
+	benchWriters := []writers.IWriter{fileWriter}
+	for i := 0; i < 2; i++ {
+		channelWriter, err := writers.NewChannelWriter(models.WriterConfiguration{Level: levels.TraceLevel}, 10000,
+			func(models.LogEvent) error { return nil })
+		if err != nil {
+			b.Fatalf("Failed to create channel writer: %v", err)
+		}
+		if err := channelWriter.Start(); err != nil {
+			b.Fatalf("Failed to start channel writer: %v", err)
+		}
+		benchWriters = append(benchWriters, channelWriter)
+	}
+
+	b.Cleanup(func() {
+		for _, writer := range benchWriters {
+			writer.Close()
+		}
+	})
+
+	return benchWriters
+}
+
+func benchmarkLogEvent(b *testing.B, benchWriters []writers.IWriter) {
+	logger := NewLogger().WithWriters(benchWriters).WithCorrelationId("bench-correlation").WithPrefix("api")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Info().
+			Str("method", "GET").
+			Str("path", "/api/v1/users").
+			Int("status", 200).
+			Int64("bytes", 5120).
+			Float64("latency_ms", 12.5).
+			Msg("Request completed")
+	}
+}
+
+// BenchmarkLogEvent_EventWriters measures the typed pipeline, where writers receive *models.LogEvent
+func BenchmarkLogEvent_EventWriters(b *testing.B) {
+	benchmarkLogEvent(b, newBenchmarkWriters(b))
+}
+
+// BenchmarkLogEvent_LegacyWriters measures the same writers driven through IWriter.Write,
+// which marshals once and unmarshals in every writer
+func BenchmarkLogEvent_LegacyWriters(b *testing.B) {
+	benchWriters := newBenchmarkWriters(b)
+	wrapped := make([]writers.IWriter, 0, len(benchWriters))
+	for _, writer := range benchWriters {
+		wrapped = append(wrapped, legacyWriter{writer})
+	}
+	benchmarkLogEvent(b, wrapped)
+}
+
+// BenchmarkLogEvent_LazyRejected measures an event whose level every writer rejects,
+// so its lazy payload is never built
+func BenchmarkLogEvent_LazyRejected(b *testing.B) {
+	benchWriters := newBenchmarkWriters(b)
+	for _, writer := range benchWriters {
+		writer.WithLevel(levels.WarnLevel.ToLogLevel())
+	}
+	logger := NewLogger().WithWriters(benchWriters)
+	payload := func() interface{} { return make([]byte, 4096) }
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Debug().Lazy("payload", payload).Msg("Request body")
+	}
+}
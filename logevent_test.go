@@ -2,9 +2,14 @@ package arbor
 
 import (
 	"errors"
+	"net"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/phuslu/log"
+	"github.com/ternarybob/arbor/models"
+	"github.com/ternarybob/arbor/writers"
 )
 
 func TestNewLogEvent(t *testing.T) {
@@ -250,3 +255,123 @@ func TestLogEvent_EndToEnd(t *testing.T) {
 		Err(errors.New("permission denied")).
 		Msg("Operation failed")
 }
+
+// eventCaptureWriter records events received through the typed IEventWriter path
+type eventCaptureWriter struct {
+	captureWriter
+	writeCalls int
+}
+
+func (ew *eventCaptureWriter) Write(p []byte) (int, error) {
+	ew.writeCalls++
+	return ew.captureWriter.Write(p)
+}
+
+func (ew *eventCaptureWriter) WriteEvent(event *models.LogEvent) error {
+	ew.mu.Lock()
+	ew.events = append(ew.events, *event)
+	ew.mu.Unlock()
+	return nil
+}
+
+func TestLogEvent_TypedPipeline(t *testing.T) {
+	eventWriter := &eventCaptureWriter{}
+	legacyWriter := &captureWriter{}
+	logger := NewLogger().WithWriters([]writers.IWriter{eventWriter, legacyWriter})
+
+	logger.Info().Int("count", 42).Int64("big", 1<<40).Msg("typed")
+
+	typed := eventWriter.Events()
+	if len(typed) != 1 {
+		t.Fatalf("Expected 1 event on typed writer, got %d", len(typed))
+	}
+	if eventWriter.writeCalls != 0 {
+		t.Error("Writers implementing IEventWriter should not receive JSON")
+	}
+	if _, ok := typed[0].Fields["count"].(int); !ok {
+		t.Errorf("Expected int field to stay int, got %T", typed[0].Fields["count"])
+	}
+	if _, ok := typed[0].Fields["big"].(int64); !ok {
+		t.Errorf("Expected int64 field to stay int64, got %T", typed[0].Fields["big"])
+	}
+
+	// Legacy writers still receive JSON via Write
+	legacy := legacyWriter.Events()
+	if len(legacy) != 1 {
+		t.Fatalf("Expected 1 event on legacy writer, got %d", len(legacy))
+	}
+	if legacy[0].Message != "typed" {
+		t.Errorf("Expected message 'typed', got %q", legacy[0].Message)
+	}
+}
+
+// requestObject implements ILogObject for the Object test
+type requestObject struct {
+	method string
+	status int
+}
+
+func (r requestObject) MarshalLogObject(event ILogEvent) {
+	event.Str("method", r.method).Int("status", r.status)
+}
+
+func TestLogEvent_RichFieldTypes(t *testing.T) {
+	eventWriter := &eventCaptureWriter{}
+	logger := NewLogger().WithWriters([]writers.IWriter{eventWriter})
+	at := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	logger.Info().
+		Any("any", []string{"a"}).
+		Interface("anyErr", errors.New("as message")).
+		Time("at", at).
+		Uint64("big", 1<<63).
+		Bytes("raw", []byte("hello")).
+		Hex("hex", []byte{0xde, 0xad}).
+		Stringer("dur", 2*time.Second).
+		Ints("ids", []int{1, 2, 3}).
+		Errs("errs", []error{errors.New("first"), nil, errors.New("second")}).
+		IPAddr("ip", net.ParseIP("10.0.0.1")).
+		Dict("dict", Dict().Str("k", "v").Dict("inner", Dict().Int("n", 1))).
+		Object("request", requestObject{method: "GET", status: 200}).
+		Msg("rich")
+
+	events := eventWriter.Events()
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(events))
+	}
+	fields := events[0].Fields
+
+	expected := map[string]interface{}{
+		"any":    []string{"a"},
+		"anyErr": "as message",
+		"at":     at,
+		"big":    uint64(1 << 63),
+		"raw":    "hello",
+		"hex":    "dead",
+		"dur":    "2s",
+		"ids":    []int{1, 2, 3},
+		"errs":   []string{"first", "second"},
+		"ip":     "10.0.0.1",
+		"dict": map[string]interface{}{
+			"k":     "v",
+			"inner": map[string]interface{}{"n": 1},
+		},
+		"request": map[string]interface{}{"method": "GET", "status": 200},
+	}
+	for key, want := range expected {
+		if !reflect.DeepEqual(fields[key], want) {
+			t.Errorf("Field %q: expected %#v, got %#v", key, want, fields[key])
+		}
+	}
+}
+
+func TestDict_IsNotWritten(t *testing.T) {
+	eventWriter := &eventCaptureWriter{}
+	_ = NewLogger().WithWriters([]writers.IWriter{eventWriter})
+
+	Dict().Str("k", "v").Msg("should not be written")
+
+	if len(eventWriter.Events()) != 0 {
+		t.Error("Dict containers should never be written")
+	}
+}
@@ -1,9 +1,11 @@
 package arbor
 
 import (
-	"runtime"
-	"strings"
+	"context"
+	"encoding/json"
+	"errors"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ternarybob/arbor/common"
@@ -24,16 +26,29 @@ const (
 	GIN_LOG_KEY        string = "gin"
 )
 
+// channelKey identifies a named channel within the registry its writer is registered in
+type channelKey struct {
+	registry IWriterRegistry
+	name     string
+}
+
 // Global tracking for channel buffers to enable proper lifecycle management
 var (
-	channelBuffers    = make(map[string]*common.ChannelBuffer)
+	channelBuffers    = make(map[channelKey]*common.ChannelBuffer)
 	channelBuffersMux sync.RWMutex
 )
 
 // logger is the main arbor logger implementation that supports multiple writers
 type logger struct {
-	writers     []writers.IWriter // Private writers for this logger instance
-	contextData map[string]string // Track context key-value pairs
+	writers     []writers.IWriter      // Private writers for this logger instance
+	registry    IWriterRegistry        // Registry for With*Writer, channels and events (nil = global registry)
+	contextData map[string]string      // Track context key-value pairs
+	fields      map[string]interface{} // Typed fields added to every event
+	level       log.Level              // Minimum level for this logger fork (0 = no threshold)
+	callerSkip  int                    // Extra stack frames to skip when reporting the caller
+	noCaller    bool                   // Disables caller capture for this logger fork
+	sampler     *writers.SampleCounter // Sampling policy shared with forks (nil = write everything)
+	hooks       []ILogHook             // Hooks run after the global hooks, in the order added
 }
 
 // SetContextChannel is deprecated. Use SetChannel("context", ch) instead.
@@ -77,20 +92,23 @@ func (l *logger) SetChannelWithBuffer(name string, ch chan []models.LogEvent, ba
 		internalLog.Debug().Msgf("Invalid flushInterval, using default: %v", flushInterval)
 	}
 
+	registry := l.writerRegistry()
+	key := channelKey{registry: registry, name: name}
+
 	// Clean up existing writer and buffer if present
 	channelBuffersMux.Lock()
-	if existingBuffer, exists := channelBuffers[name]; exists {
+	if existingBuffer, exists := channelBuffers[key]; exists {
 		internalLog.Debug().Msgf("Cleaning up existing channel buffer for '%s'", name)
 		existingBuffer.Stop()
-		delete(channelBuffers, name)
+		delete(channelBuffers, key)
 	}
 	channelBuffersMux.Unlock()
 
 	// Close existing writer if registered
-	if existingWriter := GetRegisteredWriter(name); existingWriter != nil {
+	if existingWriter := registry.GetRegisteredWriter(name); existingWriter != nil {
 		internalLog.Debug().Msgf("Closing existing writer for '%s'", name)
 		existingWriter.Close()
-		UnregisterWriter(name)
+		registry.UnregisterWriter(name)
 	}
 
 	// Create a new channel buffer instance for this named channel
@@ -130,11 +148,11 @@ func (l *logger) SetChannelWithBuffer(name string, ch chan []models.LogEvent, ba
 
 	// Track the buffer for lifecycle management
 	channelBuffersMux.Lock()
-	channelBuffers[name] = channelBuf
+	channelBuffers[key] = channelBuf
 	channelBuffersMux.Unlock()
 
-	// Register the writer in the global registry
-	RegisterWriter(name, writer)
+	// Register the writer in the logger's registry
+	registry.RegisterWriter(name, writer)
 
 	internalLog.Trace().Msgf("Channel writer '%s' registered successfully with queue size %d", name, queueSize)
 }
@@ -143,25 +161,40 @@ func (l *logger) SetChannelWithBuffer(name string, ch chan []models.LogEvent, ba
 func (l *logger) UnregisterChannel(name string) {
 	internalLog := common.NewLogger().WithContext("function", "Logger.UnregisterChannel").GetLogger()
 
+	registry := l.writerRegistry()
+	key := channelKey{registry: registry, name: name}
+
 	// Stop and remove the channel buffer
 	channelBuffersMux.Lock()
-	if buffer, exists := channelBuffers[name]; exists {
+	if buffer, exists := channelBuffers[key]; exists {
 		internalLog.Debug().Msgf("Stopping channel buffer for '%s'", name)
 		buffer.Stop()
-		delete(channelBuffers, name)
+		delete(channelBuffers, key)
 	}
 	channelBuffersMux.Unlock()
 
 	// Close and unregister the writer
-	if writer := GetRegisteredWriter(name); writer != nil {
+	if writer := registry.GetRegisteredWriter(name); writer != nil {
 		internalLog.Debug().Msgf("Closing and unregistering writer for '%s'", name)
 		writer.Close()
-		UnregisterWriter(name)
+		registry.UnregisterWriter(name)
 	}
 
 	internalLog.Trace().Msgf("Channel writer '%s' unregistered successfully", name)
 }
 
+// GetChannel returns the channel registered under name in the global registry with SetChannel,
+// SetChannelWithBuffer or a logger configuration, or nil if there is none
+func GetChannel(name string) chan []models.LogEvent {
+	channelBuffersMux.RLock()
+	defer channelBuffersMux.RUnlock()
+
+	if buffer, exists := channelBuffers[channelKey{registry: globalWriterRegistry, name: name}]; exists {
+		return buffer.Channel()
+	}
+	return nil
+}
+
 // WithContextWriter creates a logger with a correlation ID for context-specific logging.
 // It simply adds the correlation ID to tag all logs from this logger.
 // If you need to stream logs for a specific context, use SetChannel to register a named
@@ -177,16 +210,51 @@ func (l *logger) WithContextWriter(contextID string) ILogger {
 	return l.WithCorrelationId(contextID)
 }
 
+// defaultLogger boxes the default logger so it can be swapped atomically
+type defaultLogger struct {
+	logger ILogger
+}
+
 var (
-	defaultLog ILogger
+	defaultLog atomic.Pointer[defaultLogger]
 )
 
-// Logger returns the default logger instance, creating it if it doesn't exist
+// Logger returns the default logger instance, creating it if it doesn't exist.
+// It is safe for concurrent use, including with SetDefault.
 func Logger() ILogger {
-	if defaultLog == nil {
-		defaultLog = createNewLogger()
+	if current := defaultLog.Load(); current != nil {
+		return current.logger
 	}
-	return defaultLog
+
+	// Concurrent first calls agree on a single logger
+	created := &defaultLogger{logger: createNewLogger()}
+	if defaultLog.CompareAndSwap(nil, created) {
+		return created.logger
+	}
+	return defaultLog.Load().logger
+}
+
+// SetDefault atomically replaces the logger returned by Logger and used by the package-level
+// Trace, Debug, Info, Warn, Error, Fatal and Panic functions, and returns a function that
+// restores the previous default. A nil logger installs a fresh default logger.
+//
+// Example:
+//
+//	restore := arbor.SetDefault(arbor.Logger().WithPrefix("billing"))
+//	defer restore()
+func SetDefault(l ILogger) (restore func()) {
+	if l == nil {
+		l = createNewLogger()
+	}
+	previous := defaultLog.Swap(&defaultLogger{logger: l})
+	return func() {
+		defaultLog.Store(previous)
+	}
+}
+
+// ReplaceGlobals is SetDefault under the name used by other logging libraries
+func ReplaceGlobals(l ILogger) (restore func()) {
+	return SetDefault(l)
 }
 
 // NewLogger creates a new logger instance
@@ -195,6 +263,24 @@ func NewLogger() ILogger {
 	return createNewLogger()
 }
 
+// NewLoggerWithRegistry creates a logger bound to registry: its With*Writer and channel methods
+// register writers there, and its events are written to the writers registered there instead of
+// the global registry. Forks of the logger share the registry, so plugins, tenants or parallel
+// tests can each have their own set of writers. A nil registry means the global registry.
+//
+// Example:
+//
+//	registry := arbor.NewWriterRegistry()
+//	tenantLogger := arbor.NewLoggerWithRegistry(registry).
+//		WithFileWriter(models.WriterConfiguration{Type: models.LogWriterTypeFile, FileName: "logs/acme.log"})
+//	defer arbor.ShutdownRegistry(context.Background(), registry)
+func NewLoggerWithRegistry(registry IWriterRegistry) ILogger {
+	return &logger{
+		contextData: make(map[string]string),
+		registry:    registry,
+	}
+}
+
 // createNewLogger is a helper function that creates a fresh logger instance
 func createNewLogger() ILogger {
 	// Create logger that will use registered writers
@@ -204,6 +290,14 @@ func createNewLogger() ILogger {
 	return logger
 }
 
+// writerRegistry returns the registry the logger registers its writers in and writes to
+func (l *logger) writerRegistry() IWriterRegistry {
+	if l.registry != nil {
+		return l.registry
+	}
+	return globalWriterRegistry
+}
+
 func (l *logger) WithWriters(writerList []writers.IWriter) ILogger {
 	forked := l.fork()
 	forked.writers = append([]writers.IWriter(nil), writerList...)
@@ -216,7 +310,7 @@ func (l *logger) WithConsoleWriter(configuration models.WriterConfiguration) ILo
 
 	// Create and register the console writer
 	consoleWriter := writers.ConsoleWriter(configuration)
-	RegisterWriter(WRITER_CONSOLE, consoleWriter)
+	l.writerRegistry().RegisterWriter(WRITER_CONSOLE, consoleWriter)
 
 	internalLog.Trace().Msg("Console writer registered successfully.")
 
@@ -230,7 +324,7 @@ func (l *logger) WithFileWriter(configuration models.WriterConfiguration) ILogge
 
 	// Create and register the file writer
 	fileWriter := writers.FileWriter(configuration)
-	RegisterWriter(WRITER_FILE, fileWriter)
+	l.writerRegistry().RegisterWriter(WRITER_FILE, fileWriter)
 
 	internalLog.Trace().Msg("File writer registered successfully.")
 
@@ -254,8 +348,9 @@ func (l *logger) WithMemoryWriter(configuration models.WriterConfiguration) ILog
 	// Register both:
 	// - LogStoreWriter handles writing log events to the store
 	// - MemoryWriter provides query interface
-	RegisterWriter(WRITER_MEMORY+"_store", logStoreWriter)
-	RegisterWriter(WRITER_MEMORY, memoryWriter)
+	registry := l.writerRegistry()
+	registry.RegisterWriter(WRITER_MEMORY+"_store", logStoreWriter)
+	registry.RegisterWriter(WRITER_MEMORY, memoryWriter)
 
 	internalLog.Trace().Msg("Memory writer and log store registered successfully.")
 
@@ -269,7 +364,7 @@ func (l *logger) WithLogStore(store writers.ILogStore, configuration models.Writ
 
 	// Create a LogStoreWriter that writes to the caller-provided store
 	logStoreWriter := writers.LogStoreWriter(store, configuration)
-	RegisterWriter(WRITER_LOGSTORE, logStoreWriter)
+	l.writerRegistry().RegisterWriter(WRITER_LOGSTORE, logStoreWriter)
 
 	internalLog.Trace().Msg("Log store writer registered successfully.")
 
@@ -309,12 +404,13 @@ func (l *logger) ClearCorrelationId() ILogger {
 	return forked
 }
 
-// ClearContext removes all context data from the logger
+// ClearContext removes all context data and fields from the logger
 func (l *logger) ClearContext() ILogger {
 	internalLog := common.NewLogger().WithContext("function", "Logger.ClearContext").GetLogger()
 
 	forked := l.fork()
 	forked.contextData = make(map[string]string)
+	forked.fields = nil
 	internalLog.Debug().Msg("Cleared all context data from logger")
 	return forked
 }
@@ -334,7 +430,8 @@ func (l *logger) WithPrefix(value string) ILogger {
 	return forked
 }
 
-// WithLevelFromString applies a log level from a string configuration
+// WithLevelFromString returns a fork of the logger with its minimum level parsed from a string.
+// Invalid strings fall back to INFO. Registered writers are not modified.
 func (l *logger) WithLevelFromString(levelStr string) ILogger {
 	internalLog := common.NewLogger().WithContext("function", "Logger.WithLevelFromString").GetLogger()
 
@@ -344,48 +441,59 @@ func (l *logger) WithLevelFromString(levelStr string) ILogger {
 	phusluLevel, err := ParseLevelString(levelStr)
 	if err != nil {
 		internalLog.Warn().Err(err).Msgf("Invalid log level '%s', using INFO", levelStr)
-		forked.applyLevel(InfoLevel, internalLog)
+		forked.level = log.InfoLevel
 	} else {
-		// Convert phuslu log.Level to our LogLevel
-		arborLevel := LogLevel(phusluLevel)
-		forked.applyLevel(arborLevel, internalLog)
+		forked.level = phusluLevel
 		internalLog.Debug().Msgf("Set log level to: %s", levelStr)
 	}
 
 	return forked
 }
 
+// WithLevel returns a fork of the logger with its own minimum level.
+// Events below the level are discarded before any writer is invoked;
+// the levels of the registered writers are not modified.
 func (l *logger) WithLevel(level LogLevel) ILogger {
-
-	internalLog := common.NewLogger().WithContext("function", "Logger.WithLevel").GetLogger()
 	forked := l.fork()
-	forked.applyLevel(level, internalLog)
+	if level == Disabled {
+		forked.level = log.PanicLevel + 1
+	} else {
+		forked.level = ParseLogLevel(int(level))
+	}
 	return forked
 }
 
-func (l *logger) applyLevel(level LogLevel, internalLog log.Logger) {
-	lvl := ParseLogLevel(int(level))
+// enabled reports whether an event at the given level passes the logger's threshold
+func (l *logger) enabled(level log.Level) bool {
+	threshold := l.threshold()
+	return threshold == 0 || level >= threshold
+}
 
-	// If the logger has its own writers, use them. Otherwise, use the global registry.
+// accepts reports whether an event at level will reach a writer: the logger's level allows it
+// and at least one of its writers accepts the level. Hooks may change an event's level, so when
+// hooks are installed every event the logger's level allows is accepted.
+func (l *logger) accepts(level log.Level) bool {
+	if !l.enabled(level) {
+		return false
+	}
+	if l.hasHooks() {
+		return true
+	}
 	if l.writers != nil {
-		internalLog.Trace().Msg("Iterating over private writers")
-		for _, writer := range l.writers {
-			writer.WithLevel(lvl)
-		}
-	} else {
-		internalLog.Trace().Msg("Iterating over registered writers")
-		// Get all registered writers
-		registeredWriters := GetAllRegisteredWriters()
-		if len(registeredWriters) == 0 {
-			internalLog.Trace().Msg("No writers registered.")
-			return
-		}
+		return writersAccept(l.writers, level)
+	}
+	return registryAccepts(l.writerRegistry(), level)
+}
 
-		for key, writer := range registeredWriters {
-			internalLog.Trace().Msgf("Key: \"%s\", Writer Type: %T\n", key, writer)
-			writer.WithLevel(lvl)
+// writersAccept reports whether any writer accepts level; writers that do not report a level accept all
+func writersAccept(writerList []writers.IWriter, level log.Level) bool {
+	for _, writer := range writerList {
+		reporter, ok := writer.(writers.ILevelReporter)
+		if !ok || level >= reporter.GetLevel() {
+			return true
 		}
 	}
+	return false
 }
 
 func (l *logger) WithContext(key string, value string) ILogger {
@@ -407,12 +515,29 @@ func (l *logger) fork() *logger {
 		return &logger{contextData: make(map[string]string)}
 	}
 
-	forked := &logger{}
+	forked := &logger{
+		level:      l.level,
+		callerSkip: l.callerSkip,
+		noCaller:   l.noCaller,
+		sampler:    l.sampler,
+		registry:   l.registry,
+	}
 
 	if l.writers != nil {
 		forked.writers = append([]writers.IWriter(nil), l.writers...)
 	}
 
+	if l.hooks != nil {
+		forked.hooks = append([]ILogHook(nil), l.hooks...)
+	}
+
+	if l.fields != nil {
+		forked.fields = make(map[string]interface{}, len(l.fields))
+		for k, v := range l.fields {
+			forked.fields[k] = v
+		}
+	}
+
 	if l.contextData != nil {
 		forked.contextData = make(map[string]string, len(l.contextData))
 		for k, v := range l.contextData {
@@ -433,75 +558,219 @@ func (l *logger) setContext(key string, value string) {
 	l.contextData[key] = value
 }
 
+// WithCallerSkip returns a fork that skips additional stack frames when reporting the caller.
+// Wrapper libraries that log on behalf of their callers should skip their own frames.
+func (l *logger) WithCallerSkip(skip int) ILogger {
+	forked := l.fork()
+	forked.callerSkip = skip
+	return forked
+}
+
+// WithoutCaller returns a fork that does not capture the caller, avoiding
+// the runtime.Caller lookup on hot paths.
+func (l *logger) WithoutCaller() ILogger {
+	forked := l.fork()
+	forked.noCaller = true
+	return forked
+}
+
+// WithSampler returns a fork whose events are sampled before reaching any writer.
+// Forks of the returned logger share the sampler and its dropped-event counts, which are
+// written to the returned logger's writers as a summary event at the end of each reportInterval
+// (DEFAULT_SAMPLE_REPORT_INTERVAL if 0) in which events were dropped, and by Flush and Shutdown.
+// The summary passes through the returned logger's hooks and redaction but is never sampled.
+// A nil sampler disables sampling.
+func (l *logger) WithSampler(sampler writers.ISampler, reportInterval time.Duration) ILogger {
+	forked := l.fork()
+	forked.sampler = nil
+	if sampler != nil {
+		forked.sampler = writers.NewSampleCounter(sampler, reportInterval).ReportTo(func(summary *models.LogEvent) {
+			if forked.prepareEvent(summary) {
+				forked.dispatch(summary)
+			}
+		})
+	}
+	return forked
+}
+
 // Copy creates a forked copy of the logger with the same configuration and context.
 // Each call creates a new child logger that inherits its parent's context (tree-like).
 func (l *logger) Copy() ILogger {
 	return l.fork()
 }
 
-func (d *logger) getFunctionName() string {
-	// Try different caller depths to find the actual calling function
-	// Skip arbor package methods and get to the caller's function
-	for i := 1; i <= 10; i++ {
-		pc, _, _, ok := runtime.Caller(i)
-		if !ok {
-			break
+// writeEvent runs the hook chain, redaction and sampler, then sends the event to the logger's writers
+func (l *logger) writeEvent(logEvent *models.LogEvent) {
+	if !l.prepareEvent(logEvent) {
+		return
+	}
+
+	if l.sampler != nil {
+		if allowed, _ := l.sampler.Allow(logEvent); !allowed {
+			return
+		}
+	}
+
+	l.dispatch(logEvent)
+}
+
+// buildEvent creates the event model for a message with the logger's correlation ID and prefix,
+// then applies the values carried by ctx (which may be nil) and the logger's fields.
+// fields becomes the event's Fields map and may be nil.
+func (l *logger) buildEvent(ctx context.Context, level log.Level, message string, fields map[string]interface{}) *models.LogEvent {
+	logEvent := &models.LogEvent{
+		Level:     level,
+		Timestamp: time.Now(),
+		Message:   message,
+		Fields:    fields,
+	}
+
+	if correlationID, exists := l.contextData[CORRELATION_ID_KEY]; exists {
+		logEvent.CorrelationID = correlationID
+	}
+	if prefix, exists := l.contextData[PREFIX_KEY]; exists {
+		logEvent.Prefix = prefix
+	}
+
+	applyContext(ctx, logEvent)
+	l.applyFields(logEvent)
+	return logEvent
+}
+
+// writeThrough writes an event through the public methods of l, for ILogger implementations
+// other than arbor's own. l adds its own context, fields and caller and runs its own hooks.
+// Not every part of the event survives:
+//   - fatal and panic events are written at error level, as l's Fatal and Panic would terminate
+//   - the error is passed to Err as its message, so its chain and stack are added as the
+//     "errorchain" and "stack" fields
+//   - the caller and function are replaced by l's own
+func writeThrough(l ILogger, logEvent *models.LogEvent) {
+	if logEvent.CorrelationID != "" {
+		l = l.WithCorrelationId(logEvent.CorrelationID)
+	}
+	if logEvent.Prefix != "" {
+		l = l.WithPrefix(logEvent.Prefix)
+	}
+
+	var event ILogEvent
+	switch logEvent.Level {
+	case log.TraceLevel:
+		event = l.Trace()
+	case log.DebugLevel:
+		event = l.Debug()
+	case log.InfoLevel:
+		event = l.Info()
+	case log.WarnLevel:
+		event = l.Warn()
+	default:
+		event = l.Error()
+	}
+
+	if logEvent.Error != "" {
+		event = event.Err(errors.New(logEvent.Error))
+	}
+	if len(logEvent.ErrorChain) > 0 {
+		event = event.Any("errorchain", logEvent.ErrorChain)
+	}
+	if len(logEvent.Stack) > 0 {
+		event = event.Any("stack", logEvent.Stack)
+	}
+	for key, value := range logEvent.Fields {
+		event = event.Any(key, value)
+	}
+	event.Msg(logEvent.Message)
+}
+
+// prepareEvent runs the hook chain then redaction, reporting whether the event should be written
+func (l *logger) prepareEvent(logEvent *models.LogEvent) bool {
+	if !l.runHooks(logEvent) {
+		return false
+	}
+	redact(logEvent)
+	return true
+}
+
+// dispatch sends the event to the logger's writers, without hooks or sampling.
+// If the logger has its own writers, use them. Otherwise, use the logger's registry.
+// Writers implementing IEventWriter receive the event directly; legacy writers
+// receive JSON, marshalled at most once per event.
+func (l *logger) dispatch(logEvent *models.LogEvent) {
+	var jsonData []byte
+
+	write := func(writer writers.IWriter) {
+		if eventWriter, ok := writer.(writers.IEventWriter); ok {
+			eventWriter.WriteEvent(logEvent)
+			return
 		}
 
-		fn := runtime.FuncForPC(pc)
-		if fn == nil {
-			continue
+		if jsonData == nil {
+			data, err := json.Marshal(logEvent)
+			if err != nil {
+				return // Or handle error appropriately
+			}
+			jsonData = data
 		}
+		writer.Write(jsonData)
+	}
 
-		funcName := fn.Name()
-		// Skip internal arbor methods, runtime, and testing functions
-		if !strings.Contains(funcName, "arbor.") &&
-			!strings.Contains(funcName, "runtime.") &&
-			!strings.Contains(funcName, "testing.") &&
-			funcName != "" {
-			return funcName
+	if l.writers != nil {
+		for _, writer := range l.writers {
+			write(writer)
+		}
+	} else {
+		registeredWriters, release := acquireWriters(l.writerRegistry())
+		defer release()
+		for _, writer := range registeredWriters {
+			write(writer)
 		}
 	}
+}
 
-	// If we can't find a good function name, return empty
-	return ""
+// newEvent creates a log event, or a no-op event if the level is below the logger's threshold
+func (l *logger) newEvent(level log.Level) ILogEvent {
+	// Fatal and Panic terminate even when their event is not written, so they always get a real event
+	if level < log.FatalLevel && !l.enabled(level) {
+		return disabledEvent
+	}
+	return newLogEvent(l, level)
 }
 
 // Fluent logging methods
 func (l *logger) Trace() ILogEvent {
-	return newLogEvent(l, log.TraceLevel)
+	return l.newEvent(log.TraceLevel)
 }
 
 func (l *logger) Debug() ILogEvent {
-	return newLogEvent(l, log.DebugLevel)
+	return l.newEvent(log.DebugLevel)
 }
 
 func (l *logger) Info() ILogEvent {
-	return newLogEvent(l, log.InfoLevel)
+	return l.newEvent(log.InfoLevel)
 }
 
 func (l *logger) Warn() ILogEvent {
-	return newLogEvent(l, log.WarnLevel)
+	return l.newEvent(log.WarnLevel)
 }
 
 func (l *logger) Error() ILogEvent {
-	return newLogEvent(l, log.ErrorLevel)
+	return l.newEvent(log.ErrorLevel)
 }
 
 func (l *logger) Fatal() ILogEvent {
-	return newLogEvent(l, log.FatalLevel)
+	return l.newEvent(log.FatalLevel)
 }
 
 func (l *logger) Panic() ILogEvent {
-	return newLogEvent(l, log.PanicLevel)
+	return l.newEvent(log.PanicLevel)
 }
 
-// GetLogger returns the default logger instance from the registry
+// GetLogger returns the default logger instance; see Logger
 func GetLogger() ILogger {
 	return Logger()
 }
 
-// Global convenience functions for direct logging
+// Global convenience functions for direct logging. Each call reads the default logger once, so
+// an event is built and written by a single logger even while SetDefault replaces it.
 func Trace() ILogEvent {
 	return GetLogger().Trace()
 }
@@ -541,7 +810,7 @@ func (l *logger) GetMemoryLogs(correlationid string, minLevel LogLevel) (map[str
 	internalLog.Context = log.NewContext(nil).Str("function", "GetMemoryLogs").Value()
 
 	// Get memory writer from registry
-	memoryWriter := GetRegisteredMemoryWriter(WRITER_MEMORY)
+	memoryWriter := l.writerRegistry().GetRegisteredMemoryWriter(WRITER_MEMORY)
 	if memoryWriter == nil {
 		internalLog.Warn().Msg("Memory writer not registered -> return")
 		return make(map[string]string), nil
@@ -556,7 +825,7 @@ func (l *logger) GetMemoryLogsWithLimit(limit int) (map[string]string, error) {
 	internalLog := common.NewLogger().WithContext("function", "Logger.GetMemoryLogsWithLimit").GetLogger()
 
 	// Get memory writer from registry
-	memoryWriter := GetRegisteredMemoryWriter(WRITER_MEMORY)
+	memoryWriter := l.writerRegistry().GetRegisteredMemoryWriter(WRITER_MEMORY)
 	if memoryWriter == nil {
 		internalLog.Warn().Msg("Memory writer not registered -> return")
 		return make(map[string]string), nil
@@ -569,8 +838,9 @@ func (l *logger) GetMemoryLogsWithLimit(limit int) (map[string]string, error) {
 func (l *logger) GinWriter(config models.WriterConfiguration) interface{} {
 	internalLog := common.NewLogger().WithContext("function", "Logger.GinWriter").GetLogger()
 
-	// Create Gin transformer with provided configuration and registry function
-	ginTransformer := transformers.NewGinTransformer(config, GetAllRegisteredWriters)
+	// Create Gin transformer with provided configuration and registry function.
+	// Gin events pass through the same hooks and redaction as the logger's own events.
+	ginTransformer := transformers.NewGinTransformerWithProcessor(config, l.writerRegistry().GetAllRegisteredWriters, l.prepareEvent)
 	internalLog.Debug().Msg("Created Gin transformer")
 
 	return ginTransformer
@@ -579,7 +849,7 @@ func (l *logger) GinWriter(config models.WriterConfiguration) interface{} {
 // GetLogFilePath returns the configured log file path if a file writer is registered
 func (l *logger) GetLogFilePath() string {
 	// Get file writer from registry
-	fileWriter := GetRegisteredWriter(WRITER_FILE)
+	fileWriter := l.writerRegistry().GetRegisteredWriter(WRITER_FILE)
 	if fileWriter == nil {
 		return "" // No file writer registered
 	}
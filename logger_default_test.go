@@ -0,0 +1,86 @@
+package arbor
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_ConcurrentFirstUse(t *testing.T) {
+	saved := defaultLog.Swap(nil)
+	t.Cleanup(func() { defaultLog.Store(saved) })
+
+	const goroutines = 16
+	loggers := make([]ILogger, goroutines)
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			loggers[i] = Logger()
+		}(i)
+	}
+	wg.Wait()
+
+	for _, l := range loggers {
+		assert.Same(t, loggers[0].(*logger), l.(*logger), "every caller gets the same default logger")
+	}
+}
+
+func TestSetDefault(t *testing.T) {
+	original := Logger()
+	emitter, capture := newCaptureLogger()
+
+	restore := SetDefault(emitter.WithPrefix("billing"))
+
+	Info().Msg("package level")
+	GetLogger().Warn().Msg("from GetLogger")
+	FromContext(context.Background()).Error().Msg("from context")
+
+	events := capture.Events()
+	require.Len(t, events, 3)
+	for _, event := range events {
+		assert.Equal(t, "billing", event.Prefix, event.Message)
+	}
+
+	restore()
+	assert.Same(t, original.(*logger), Logger().(*logger), "restore reinstates the previous default")
+
+	restore = ReplaceGlobals(nil)
+	assert.NotSame(t, original.(*logger), Logger().(*logger), "nil installs a fresh default logger")
+	restore()
+	assert.Same(t, original.(*logger), Logger().(*logger))
+}
+
+func TestSetDefault_ConcurrentWithLogging(t *testing.T) {
+	emitterA, captureA := newCaptureLogger()
+	emitterB, captureB := newCaptureLogger()
+	restore := SetDefault(emitterA)
+	t.Cleanup(restore)
+
+	const events = 200
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < events; i++ {
+			Info().Int("i", i).Msg("event")
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < events; i++ {
+			if i%2 == 0 {
+				SetDefault(emitterB)
+			} else {
+				SetDefault(emitterA)
+			}
+		}
+	}()
+	wg.Wait()
+
+	assert.Equal(t, events, len(captureA.Events())+len(captureB.Events()), "each event is written by exactly one default logger")
+}
@@ -0,0 +1,89 @@
+package arbor
+
+import (
+	"testing"
+
+	"github.com/phuslu/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ternarybob/arbor/levels"
+	"github.com/ternarybob/arbor/models"
+	"github.com/ternarybob/arbor/writers"
+)
+
+func TestLogger_WithLevel_FiltersOnlyThisFork(t *testing.T) {
+	base, capture := newCaptureLogger()
+	warnLogger := base.WithLevel(WarnLevel)
+	debugLogger := warnLogger.WithLevel(DebugLevel)
+
+	warnLogger.Debug().Msg("dropped")
+	warnLogger.Info().Msg("dropped")
+	warnLogger.Warn().Msg("warn kept")
+	debugLogger.Debug().Msg("debug kept")
+	debugLogger.Trace().Msg("dropped")
+	base.Trace().Msg("trace kept")
+
+	events := capture.Events()
+	require.Len(t, events, 3)
+	assert.Equal(t, "warn kept", events[0].Message)
+	assert.Equal(t, "debug kept", events[1].Message)
+	assert.Equal(t, "trace kept", events[2].Message)
+}
+
+func TestLogger_WithLevel_DoesNotMutateWriters(t *testing.T) {
+	var processed []models.LogEvent
+	channelWriter, err := writers.NewChannelWriter(models.WriterConfiguration{Level: levels.InfoLevel}, 100,
+		func(event models.LogEvent) error {
+			processed = append(processed, event)
+			return nil
+		})
+	require.NoError(t, err)
+	require.NoError(t, channelWriter.Start())
+
+	RegisterWriter("level-scope-test", channelWriter)
+	defer func() {
+		UnregisterWriter("level-scope-test")
+		channelWriter.Close()
+	}()
+
+	// Lowering a sub-logger must not lower the writer's own level
+	NewLogger().WithLevel(TraceLevel).Debug().Msg("below writer level")
+	NewLogger().WithLevelFromString("trace").Debug().Msg("below writer level")
+	NewLogger().Info().Msg("at writer level")
+
+	require.NoError(t, channelWriter.Stop())
+	require.Len(t, processed, 1)
+	assert.Equal(t, "at writer level", processed[0].Message)
+}
+
+func TestLogger_WithLevel_Disabled(t *testing.T) {
+	base, capture := newCaptureLogger()
+
+	base.WithLevel(Disabled).Warn().Msg("dropped")
+	base.WithLevelFromString("off").Error().Msg("dropped")
+	assert.PanicsWithValue(t, "still panics", func() {
+		base.WithLevel(Disabled).Panic().Msg("still panics")
+	})
+
+	assert.Empty(t, capture.Events())
+}
+
+func TestLogger_DisabledEventIsNoop(t *testing.T) {
+	l := NewLogger().WithLevel(InfoLevel)
+
+	event := l.Debug()
+	assert.Equal(t, disabledEvent, event)
+	assert.Equal(t, disabledEvent, event.Str("k", "v").Int("n", 1).Err(nil))
+
+	allocs := testing.AllocsPerRun(100, func() {
+		l.Debug().Str("key", "value").Int("count", 1).Msg("disabled")
+	})
+	assert.Zero(t, allocs, "disabled events should not allocate")
+}
+
+func TestLogger_WithLevel_InheritedByForks(t *testing.T) {
+	l := NewLogger().WithLevel(ErrorLevel).WithPrefix("db").WithCorrelationId("c-1").Copy()
+
+	assert.Equal(t, log.ErrorLevel, l.(*logger).level)
+	assert.Equal(t, disabledEvent, l.Warn())
+}
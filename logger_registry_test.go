@@ -0,0 +1,118 @@
+package arbor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/phuslu/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ternarybob/arbor/models"
+)
+
+func TestNewLoggerWithRegistry_IsolatesWriters(t *testing.T) {
+	isolateRegistry(t)
+	global := &captureWriter{}
+	RegisterWriter("global", global)
+
+	tenantA, tenantB := NewWriterRegistry(), NewWriterRegistry()
+	captureA, captureB := &captureWriter{}, &captureWriter{}
+	tenantA.RegisterWriter("capture", captureA)
+	tenantB.RegisterWriter("capture", captureB)
+
+	NewLoggerWithRegistry(tenantA).WithPrefix("a").Info().Msg("from a")
+	NewLoggerWithRegistry(tenantB).WithCorrelationId("b-1").Info().Msg("from b")
+	NewLogger().Info().Msg("from global")
+
+	require.Len(t, captureA.Events(), 1)
+	assert.Equal(t, "from a", captureA.Events()[0].Message, "forks keep the registry")
+	require.Len(t, captureB.Events(), 1)
+	assert.Equal(t, "from b", captureB.Events()[0].Message)
+	require.Len(t, global.Events(), 1)
+	assert.Equal(t, "from global", global.Events()[0].Message)
+
+	assert.Equal(t, 1, GetWriterCount())
+	assert.Same(t, globalWriterRegistry, NewLoggerWithRegistry(nil).(*logger).writerRegistry(), "nil means the global registry")
+}
+
+func TestNewLoggerWithRegistry_WithWriters(t *testing.T) {
+	isolateRegistry(t)
+	registry := NewWriterRegistry()
+	t.Cleanup(func() { ShutdownRegistry(context.Background(), registry) })
+
+	tenant := NewLoggerWithRegistry(registry).
+		WithConsoleWriter(models.WriterConfiguration{Type: models.LogWriterTypeConsole, Level: ErrorLevel}).
+		WithFileWriter(models.WriterConfiguration{Type: models.LogWriterTypeFile, FileName: t.TempDir() + "/tenant.log"}).
+		WithMemoryWriter(models.WriterConfiguration{Type: models.LogWriterTypeMemory})
+
+	assert.ElementsMatch(t, []string{WRITER_CONSOLE, WRITER_FILE, WRITER_MEMORY, WRITER_MEMORY + "_store"}, registry.GetRegisteredWriterNames())
+	assert.Zero(t, GetWriterCount(), "nothing is registered globally")
+	assert.Contains(t, tenant.GetLogFilePath(), "tenant.log")
+
+	tenant.WithCorrelationId("tenant-1").Info().Msg("stored")
+
+	assert.Eventually(t, func() bool {
+		logs, err := tenant.GetMemoryLogsForCorrelation("tenant-1")
+		return err == nil && len(logs) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	logs, err := NewLogger().GetMemoryLogsForCorrelation("tenant-1")
+	require.NoError(t, err)
+	assert.Empty(t, logs, "the global logger has no memory writer")
+}
+
+func TestNewLoggerWithRegistry_Channels(t *testing.T) {
+	isolateRegistry(t)
+	registry := NewWriterRegistry()
+
+	globalCh := make(chan []models.LogEvent, 4)
+	tenantCh := make(chan []models.LogEvent, 4)
+	NewLogger().SetChannelWithBuffer("events", globalCh, 1, 10*time.Millisecond)
+	t.Cleanup(func() { NewLogger().UnregisterChannel("events") })
+
+	tenant := NewLoggerWithRegistry(registry)
+	tenant.SetChannelWithBuffer("events", tenantCh, 1, 10*time.Millisecond)
+
+	require.NotNil(t, registry.GetRegisteredWriter("events"))
+	assert.Equal(t, globalCh, GetChannel("events"), "channels of the same name do not replace each other")
+
+	tenant.Info().Msg("tenant event")
+
+	select {
+	case batch := <-tenantCh:
+		require.Len(t, batch, 1)
+		assert.Equal(t, "tenant event", batch[0].Message)
+	case <-time.After(time.Second):
+		t.Fatal("Expected the tenant channel to receive the event")
+	}
+	assert.Empty(t, globalCh)
+
+	require.NoError(t, ShutdownRegistry(context.Background(), registry))
+	assert.Zero(t, registry.GetWriterCount())
+	assert.NotNil(t, GetRegisteredWriter("events"), "shutting down a registry leaves the global registry running")
+	assert.Equal(t, globalCh, GetChannel("events"))
+}
+
+// mapRegistry is an IWriterRegistry implementation other than WriterRegistry
+type mapRegistry struct {
+	IWriterRegistry
+}
+
+func TestNewLoggerWithRegistry_CustomRegistry(t *testing.T) {
+	registry := &mapRegistry{NewWriterRegistry()}
+	capture := &leveledCaptureWriter{level: log.WarnLevel}
+	registry.RegisterWriter("capture", capture)
+
+	tenant := NewLoggerWithRegistry(registry)
+	tenant.Info().Lazy("expensive", func() interface{} {
+		t.Error("Expected the lazy field not to be evaluated for a rejected level")
+		return nil
+	}).Msg("dropped")
+	tenant.Warn().Msg("written")
+
+	events := capture.Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, "written", events[0].Message)
+	assert.NoError(t, FlushRegistry(context.Background(), registry))
+}
@@ -0,0 +1,82 @@
+package arbor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ternarybob/arbor/models"
+	"github.com/ternarybob/arbor/writers"
+)
+
+func TestLogger_WithSampler(t *testing.T) {
+	emitter, capture := newCaptureLogger()
+	sampled := emitter.WithSampler(writers.NewBurstSampler(2, 0, time.Hour), 20*time.Millisecond)
+
+	for i := 0; i < 10; i++ {
+		sampled.Info().Msg("flood")
+	}
+	// Forks share the sampler's counts
+	sampled.WithPrefix("child").Info().Msg("flood")
+	// The parent logger is not sampled
+	emitter.Info().Msg("flood")
+
+	events := capture.Events()
+	require.Len(t, events, 3)
+
+	// The summary is written when the interval ends, without waiting for another event
+	time.Sleep(60 * time.Millisecond)
+
+	events = capture.Events()
+	require.Len(t, events, 4)
+	assert.Equal(t, "sampler", events[3].Prefix)
+	assert.EqualValues(t, 9, events[3].Fields["dropped"])
+}
+
+func TestLogger_WithSamplerFlushReportsDrops(t *testing.T) {
+	isolateRegistry(t)
+	emitter, capture := newCaptureLogger()
+	sampled := emitter.WithSampler(writers.NewRandomSampler(0), time.Hour)
+
+	sampled.Info().Msg("dropped")
+	sampled.Info().Msg("dropped")
+	require.Empty(t, capture.Events())
+
+	require.NoError(t, Flush(context.Background()))
+
+	events := capture.Events()
+	require.Len(t, events, 1, "Flush reports outstanding drops")
+	assert.EqualValues(t, 2, events[0].Fields["dropped"])
+}
+
+func TestLogger_WithSamplerSummaryRunsHooks(t *testing.T) {
+	isolateRegistry(t)
+	emitter, capture := newCaptureLogger()
+
+	var hooked []string
+	sampled := emitter.WithHook(LogHookFunc(func(event *models.LogEvent) bool {
+		hooked = append(hooked, event.Message)
+		event.Fields["hooked"] = true
+		return true
+	})).WithSampler(writers.NewRandomSampler(0), time.Hour)
+
+	sampled.Info().Msg("dropped")
+	require.NoError(t, Flush(context.Background()))
+
+	events := capture.Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, "sampler", events[0].Prefix)
+	assert.Equal(t, true, events[0].Fields["hooked"], "the summary passes through the logger's hooks")
+	assert.Len(t, hooked, 2, "hooks run on the dropped event and on the summary")
+}
+
+func TestLogger_WithSamplerNilDisables(t *testing.T) {
+	emitter, capture := newCaptureLogger()
+	unsampled := emitter.WithSampler(writers.NewRandomSampler(0), 0).WithSampler(nil, 0)
+
+	unsampled.Info().Msg("kept")
+
+	assert.Len(t, capture.Events(), 1)
+}
@@ -2,7 +2,6 @@ package arbor
 
 import (
 	"errors"
-	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -280,19 +279,6 @@ func TestParseLogLevel(t *testing.T) {
 	}
 }
 
-func TestLogger_GetFunctionName(t *testing.T) {
-	logger := Logger().(*logger)
-
-	// This will test the function name detection
-	funcName := logger.getFunctionName()
-
-	// Should contain test function name or be empty (acceptable for edge cases)
-	if funcName != "" && !strings.Contains(funcName, "Test") {
-		// This is informational - function name detection can vary
-		t.Logf("Function name detected: %s", funcName)
-	}
-}
-
 func TestLogger_ChainedUsage(t *testing.T) {
 	// Test complex chained usage
 	logger := Logger().WithCorrelationId("test-123").WithPrefix("TEST")
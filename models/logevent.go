@@ -10,9 +10,27 @@ type LogEvent struct {
 	Level         log.Level              `json:"level"`
 	Timestamp     time.Time              `json:"time"`
 	CorrelationID string                 `json:"correlationid"`
+	TraceID       string                 `json:"traceid,omitempty"` // OpenTelemetry trace ID, 32 hex characters
+	SpanID        string                 `json:"spanid,omitempty"`  // OpenTelemetry span ID, 16 hex characters
 	Prefix        string                 `json:"prefix"`
 	Message       string                 `json:"message"`
 	Error         string                 `json:"error"`
+	ErrorChain    []ErrorDetail          `json:"errorchain,omitempty"`
+	Stack         []string               `json:"stack,omitempty"`
 	Function      string                 `json:"function"`
+	Caller        *CallerInfo            `json:"caller,omitempty"`
 	Fields        map[string]interface{} `json:"fields"`
 }
+
+// ErrorDetail describes one error in a wrapped error chain
+type ErrorDetail struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+}
+
+// CallerInfo identifies the source location that emitted an event
+type CallerInfo struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Function string `json:"function"`
+}
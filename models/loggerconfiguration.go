@@ -0,0 +1,103 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// LoggerConfiguration describes a complete logger: its writers, channels, levels and context fields.
+// It is read by arbor.FromConfig (JSON or YAML), arbor.FromEnv and arbor.WatchConfig.
+type LoggerConfiguration struct {
+	Level     string                  `json:"level,omitempty"`     // Default minimum level, e.g. "info"
+	LevelSpec string                  `json:"levelspec,omitempty"` // Per-prefix levels, e.g. "info,db=debug"
+	Prefix    string                  `json:"prefix,omitempty"`
+	Fields    map[string]interface{}  `json:"fields,omitempty"` // Added to every event
+	Writers   []WriterConfiguration   `json:"writers,omitempty"`
+	Channels  []ChannelConfiguration  `json:"channels,omitempty"`
+	Redaction *RedactionConfiguration `json:"redaction,omitempty"`
+}
+
+// RedactionConfiguration describes the redaction applied to every event.
+// Keys and Patterns share one action: "mask" (default), "hash" or "drop".
+type RedactionConfiguration struct {
+	Defaults bool     `json:"defaults,omitempty"` // Apply arbor's default rules for secrets and PII
+	Keys     []string `json:"keys,omitempty"`     // Case-insensitive substrings of field names
+	Patterns []string `json:"patterns,omitempty"` // Regular expressions matched in messages, errors and fields
+	Action   string   `json:"action,omitempty"`
+}
+
+// ChannelConfiguration describes a named channel that receives batches of events.
+// The channel is created with the given capacity and registered with SetChannelWithBuffer.
+type ChannelConfiguration struct {
+	Name          string        `json:"name"`
+	BatchSize     int           `json:"batchsize,omitempty"`     // Events per batch (default 5)
+	FlushInterval time.Duration `json:"flushinterval,omitempty"` // Longest wait before a partial batch is sent (default 1s)
+	Capacity      int           `json:"capacity,omitempty"`      // Batches the channel holds (default 100)
+}
+
+// UnmarshalJSON accepts the flush interval as a duration string such as "500ms" or as nanoseconds
+func (c *ChannelConfiguration) UnmarshalJSON(data []byte) error {
+	type plain ChannelConfiguration
+	raw := struct {
+		*plain
+		FlushInterval json.RawMessage `json:"flushinterval,omitempty"`
+	}{plain: (*plain)(c)}
+
+	if err := decodeStrict(data, &raw); err != nil {
+		return err
+	}
+
+	interval, err := parseJSONDuration(raw.FlushInterval)
+	if err != nil {
+		return fmt.Errorf("flushinterval: %w", err)
+	}
+	c.FlushInterval = interval
+	return nil
+}
+
+// UnmarshalJSON accepts the window as a duration string such as "10s" or as nanoseconds
+func (d *DedupConfiguration) UnmarshalJSON(data []byte) error {
+	type plain DedupConfiguration
+	raw := struct {
+		*plain
+		Window json.RawMessage `json:"window"`
+	}{plain: (*plain)(d)}
+
+	if err := decodeStrict(data, &raw); err != nil {
+		return err
+	}
+
+	window, err := parseJSONDuration(raw.Window)
+	if err != nil {
+		return fmt.Errorf("window: %w", err)
+	}
+	d.Window = window
+	return nil
+}
+
+// decodeStrict decodes JSON, rejecting unknown fields so configuration typos are reported
+func decodeStrict(data []byte, value interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(value)
+}
+
+// parseJSONDuration reads a duration string or a number of nanoseconds; missing values are 0
+func parseJSONDuration(raw json.RawMessage) (time.Duration, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return 0, nil
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return time.ParseDuration(text)
+	}
+
+	var nanoseconds int64
+	if err := json.Unmarshal(raw, &nanoseconds); err != nil {
+		return 0, fmt.Errorf("must be a duration such as \"10s\", got %s", raw)
+	}
+	return time.Duration(nanoseconds), nil
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ternarybob/arbor/levels"
+)
+
+// OTLPConfiguration configures the export of events to an OpenTelemetry collector
+// using OTLP over HTTP with JSON encoding
+type OTLPConfiguration struct {
+	Endpoint           string            `json:"endpoint,omitempty"` // Logs URL (default http://localhost:4318/v1/logs)
+	Headers            map[string]string `json:"headers,omitempty"`  // Added to every request, e.g. authorization
+	ServiceName        string            `json:"servicename,omitempty"`
+	ResourceAttributes map[string]string `json:"resourceattributes,omitempty"`
+	Level              levels.LogLevel   `json:"level"`
+	BatchSize          int               `json:"batchsize,omitempty"`     // Events per request (default 512)
+	FlushInterval      time.Duration     `json:"flushinterval,omitempty"` // Longest wait before a partial batch is sent (default 5s)
+	QueueSize          int               `json:"queuesize,omitempty"`     // Events waiting to be sent; more are dropped (default 2048)
+	Timeout            time.Duration     `json:"timeout,omitempty"`       // Per request (default 10s)
+	MaxRetries         int               `json:"maxretries,omitempty"`    // Retries of a failed request (default 3, negative for none)
+	RetryBackoff       time.Duration     `json:"retrybackoff,omitempty"`  // First retry delay, doubled for each retry (default 500ms)
+	Client             *http.Client      `json:"-"`                       // Optional client, e.g. for TLS settings
+}
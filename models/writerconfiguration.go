@@ -2,6 +2,7 @@ package models
 
 import (
 	"io"
+	"time"
 
 	"github.com/ternarybob/arbor/levels"
 )
@@ -25,15 +26,24 @@ const (
 )
 
 type WriterConfiguration struct {
-	Type             LogWriterType   `json:"type"`
-	Writer           io.Writer       `json:"-"`
-	Level            levels.LogLevel `json:"level"`
-	TimeFormat       string          `json:"timeformat"`
-	FileName         string          `json:"filepath,omitempty"`
-	LogNameFormat    string          `json:"lognameformat,omitempty"`
-	MaxSize          int64           `json:"buffersize,omitempty"`
-	MaxBackups       int             `json:"maxfiles,omitempty"`
-	DisableTimestamp bool            `json:"disabletimestamp,omitempty"`
-	OutputType       OutputFormat    `json:"outputtype,omitempty"`
-	DBPath           string          `json:"dbpath,omitempty"`
+	Type             LogWriterType       `json:"type"`
+	Writer           io.Writer           `json:"-"`
+	Level            levels.LogLevel     `json:"level"`
+	TimeFormat       string              `json:"timeformat"`
+	FileName         string              `json:"filepath,omitempty"`
+	LogNameFormat    string              `json:"lognameformat,omitempty"`
+	MaxSize          int64               `json:"buffersize,omitempty"`
+	MaxBackups       int                 `json:"maxfiles,omitempty"`
+	DisableTimestamp bool                `json:"disabletimestamp,omitempty"`
+	OutputType       OutputFormat        `json:"outputtype,omitempty"`
+	DBPath           string              `json:"dbpath,omitempty"`
+	Dedup            *DedupConfiguration `json:"dedup,omitempty"`
+}
+
+// DedupConfiguration collapses repeated events written to a writer.
+// Events are repeats when their level, prefix, message and the values of Fields match.
+type DedupConfiguration struct {
+	Window time.Duration `json:"window"`           // Period over which repeats are collapsed
+	Burst  int           `json:"burst,omitempty"`  // Identical events written per window before suppressing (default 1)
+	Fields []string      `json:"fields,omitempty"` // Field keys that also distinguish events
 }
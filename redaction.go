@@ -0,0 +1,335 @@
+package arbor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"github.com/ternarybob/arbor/models"
+)
+
+// REDACTED_MASK replaces sensitive values redacted with RedactMask
+const REDACTED_MASK = "[REDACTED]"
+
+// RedactAction is what a redaction rule does with the sensitive data it finds
+type RedactAction int
+
+const (
+	// RedactMask replaces the sensitive data with REDACTED_MASK
+	RedactMask RedactAction = iota
+	// RedactHash replaces the sensitive data with a short SHA-256 digest, so equal values
+	// can still be correlated across events without being revealed
+	RedactHash
+	// RedactDrop removes the field; pattern matches in the message and error are removed
+	RedactDrop
+)
+
+// RedactionRule describes sensitive data and what to do with it.
+// A rule matches field names (Keys), text (Pattern), or both.
+type RedactionRule struct {
+	Name string
+	// Keys are case-insensitive substrings of field names; the whole value of a matching field is redacted
+	Keys []string
+	// Pattern is applied to the message, the error and string field values; only the match is redacted
+	Pattern *regexp.Regexp
+	// Validate optionally confirms a Pattern match, reducing false positives
+	Validate func(match string) bool
+	Action   RedactAction
+}
+
+// Redactor applies redaction rules to events before they reach any writer.
+// It implements ILogHook, so it can also be attached to a single logger with WithHook.
+type Redactor struct {
+	rules []RedactionRule
+}
+
+// NewRedactor creates a redactor applying the rules in order
+func NewRedactor(rules ...RedactionRule) *Redactor {
+	copied := make([]RedactionRule, len(rules))
+	for i, rule := range rules {
+		rule.Keys = make([]string, len(rule.Keys))
+		for j, key := range rules[i].Keys {
+			rule.Keys[j] = strings.ToLower(key)
+		}
+		copied[i] = rule
+	}
+	return &Redactor{rules: copied}
+}
+
+// activeRedactor is the redactor applied to every event, set with SetRedactor
+var activeRedactor atomic.Pointer[Redactor]
+
+// SetRedactor sets the redactor applied to every event after the hook chain and before
+// sampling and the writers, including the log store and its BoltDB persistence.
+// Pass nil to disable redaction.
+//
+// Example:
+//
+//	arbor.SetRedactor(arbor.NewRedactor(arbor.DefaultRedactionRules()...))
+func SetRedactor(redactor *Redactor) {
+	activeRedactor.Store(redactor)
+}
+
+// Run redacts the event and always keeps it
+func (r *Redactor) Run(logEvent *models.LogEvent) bool {
+	r.Redact(logEvent)
+	return true
+}
+
+// Redact applies the rules to the event's message, error, error chain and fields.
+// Nested maps and slices are inspected; values of other types are only redacted by key.
+func (r *Redactor) Redact(logEvent *models.LogEvent) {
+	if r == nil || len(r.rules) == 0 {
+		return
+	}
+
+	logEvent.Message = r.redactText(logEvent.Message)
+	logEvent.Error = r.redactText(logEvent.Error)
+	if len(logEvent.ErrorChain) > 0 {
+		chain := make([]models.ErrorDetail, len(logEvent.ErrorChain))
+		for i, detail := range logEvent.ErrorChain {
+			detail.Message = r.redactText(detail.Message)
+			chain[i] = detail
+		}
+		logEvent.ErrorChain = chain
+	}
+
+	// The top-level fields belong to the event; nested values may be shared and are copied
+	for key, value := range logEvent.Fields {
+		if redacted, keep := r.redactField(key, value); keep {
+			logEvent.Fields[key] = redacted
+		} else {
+			delete(logEvent.Fields, key)
+		}
+	}
+}
+
+// redactField redacts a field value, reporting whether the field should be kept
+func (r *Redactor) redactField(key string, value interface{}) (interface{}, bool) {
+	if rule := r.keyRule(key); rule != nil {
+		switch rule.Action {
+		case RedactDrop:
+			return nil, false
+		case RedactHash:
+			return redactHash(fmt.Sprint(value)), true
+		default:
+			return REDACTED_MASK, true
+		}
+	}
+	return r.redactValue(value)
+}
+
+// redactValue applies the pattern rules to a value, descending into maps and slices
+func (r *Redactor) redactValue(value interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case string:
+		return r.redactString(v)
+	case []string:
+		redacted := make([]string, 0, len(v))
+		for _, item := range v {
+			if text, keep := r.redactString(item); keep {
+				redacted = append(redacted, text.(string))
+			}
+		}
+		return redacted, true
+	case []interface{}:
+		redacted := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			if nested, keep := r.redactValue(item); keep {
+				redacted = append(redacted, nested)
+			}
+		}
+		return redacted, true
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			if nested, keep := r.redactField(key, item); keep {
+				redacted[key] = nested
+			}
+		}
+		return redacted, true
+	case map[string]string:
+		redacted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			if nested, keep := r.redactField(key, item); keep {
+				redacted[key] = nested
+			}
+		}
+		return redacted, true
+	default:
+		return value, true
+	}
+}
+
+// redactString applies the pattern rules to a string field value.
+// A match by a RedactDrop rule drops the whole value.
+func (r *Redactor) redactString(text string) (interface{}, bool) {
+	for i := range r.rules {
+		rule := &r.rules[i]
+		if rule.Pattern == nil {
+			continue
+		}
+		if rule.Action == RedactDrop {
+			if rule.matches(text) {
+				return nil, false
+			}
+			continue
+		}
+		text = rule.replace(text)
+	}
+	return text, true
+}
+
+// redactText applies the pattern rules to the message or error, which are never dropped
+func (r *Redactor) redactText(text string) string {
+	if text == "" {
+		return text
+	}
+	for i := range r.rules {
+		if r.rules[i].Pattern != nil {
+			text = r.rules[i].replace(text)
+		}
+	}
+	return text
+}
+
+// keyRule returns the first rule whose keys match the field name
+func (r *Redactor) keyRule(key string) *RedactionRule {
+	var lowered string
+	for i := range r.rules {
+		rule := &r.rules[i]
+		if len(rule.Keys) == 0 {
+			continue
+		}
+		if lowered == "" {
+			lowered = strings.ToLower(key)
+		}
+		for _, pattern := range rule.Keys {
+			if strings.Contains(lowered, pattern) {
+				return rule
+			}
+		}
+	}
+	return nil
+}
+
+// matches reports whether the rule's pattern finds a valid match in text
+func (rule *RedactionRule) matches(text string) bool {
+	if rule.Validate == nil {
+		return rule.Pattern.MatchString(text)
+	}
+	for _, match := range rule.Pattern.FindAllString(text, -1) {
+		if rule.Validate(match) {
+			return true
+		}
+	}
+	return false
+}
+
+// replace redacts every valid match of the rule's pattern in text
+func (rule *RedactionRule) replace(text string) string {
+	return rule.Pattern.ReplaceAllStringFunc(text, func(match string) string {
+		if rule.Validate != nil && !rule.Validate(match) {
+			return match
+		}
+		switch rule.Action {
+		case RedactDrop:
+			return ""
+		case RedactHash:
+			return redactHash(match)
+		default:
+			return REDACTED_MASK
+		}
+	})
+}
+
+// redactHash returns a short, stable digest of a sensitive value
+func redactHash(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return "sha256:" + hex.EncodeToString(sum[:8])
+}
+
+// redact applies the active redactor, if any, to the event
+func redact(logEvent *models.LogEvent) {
+	if redactor := activeRedactor.Load(); redactor != nil {
+		redactor.Redact(logEvent)
+	}
+}
+
+var (
+	emailPattern       = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	cardNumberPattern  = regexp.MustCompile(`\b(?:\d[ \-]?){12,18}\d\b`)
+	bearerTokenPattern = regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9\-._~+/]+=*`)
+	jwtPattern         = regexp.MustCompile(`\beyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]+`)
+)
+
+// SensitiveKeys are the field name patterns redacted by DefaultRedactionRules
+var SensitiveKeys = []string{"password", "passwd", "secret", "token", "authorization", "api_key", "apikey", "cookie", "private_key"}
+
+// SensitiveKeysRule redacts fields whose names contain any of keys (SensitiveKeys if none are given)
+func SensitiveKeysRule(action RedactAction, keys ...string) RedactionRule {
+	if len(keys) == 0 {
+		keys = SensitiveKeys
+	}
+	return RedactionRule{Name: "keys", Keys: keys, Action: action}
+}
+
+// EmailRule redacts email addresses
+func EmailRule(action RedactAction) RedactionRule {
+	return RedactionRule{Name: "email", Pattern: emailPattern, Action: action}
+}
+
+// CardNumberRule redacts payment card numbers, validated with the Luhn checksum
+func CardNumberRule(action RedactAction) RedactionRule {
+	return RedactionRule{Name: "card", Pattern: cardNumberPattern, Validate: luhnValid, Action: action}
+}
+
+// BearerTokenRule redacts bearer tokens, e.g. from Authorization headers
+func BearerTokenRule(action RedactAction) RedactionRule {
+	return RedactionRule{Name: "bearer", Pattern: bearerTokenPattern, Action: action}
+}
+
+// JWTRule redacts JSON Web Tokens
+func JWTRule(action RedactAction) RedactionRule {
+	return RedactionRule{Name: "jwt", Pattern: jwtPattern, Action: action}
+}
+
+// DefaultRedactionRules masks sensitive keys, bearer tokens, JWTs, card numbers and email addresses
+func DefaultRedactionRules() []RedactionRule {
+	return []RedactionRule{
+		SensitiveKeysRule(RedactMask),
+		BearerTokenRule(RedactMask),
+		JWTRule(RedactMask),
+		CardNumberRule(RedactMask),
+		EmailRule(RedactMask),
+	}
+}
+
+// luhnValid reports whether the digits in number pass the Luhn checksum
+func luhnValid(number string) bool {
+	var sum, digits int
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c == ' ' || c == '-' {
+			continue
+		}
+		if c < '0' || c > '9' {
+			return false
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		digits++
+		double = !double
+	}
+	return digits >= 13 && digits <= 19 && sum%10 == 0
+}
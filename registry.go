@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"sync"
 
+	"github.com/phuslu/log"
 	"github.com/ternarybob/arbor/writers"
 )
 
@@ -17,8 +18,15 @@ const (
 // WriterRegistry manages a collection of named writers with thread-safe access
 // and implements the IWriterRegistry interface
 type WriterRegistry struct {
-	writers map[string]writers.IWriter
-	mu      sync.RWMutex
+	writers    map[string]writers.IWriter
+	generation *writerGeneration
+	mu         sync.RWMutex
+}
+
+// writerGeneration counts the events being written to the registry's writers since the last
+// SwapWriters, so a swap can wait until no event is still using the writers it replaced
+type writerGeneration struct {
+	inflight sync.WaitGroup
 }
 
 // Ensure WriterRegistry implements IWriterRegistry
@@ -26,7 +34,8 @@ var _ IWriterRegistry = (*WriterRegistry)(nil)
 
 // Global writer registry instance
 var globalWriterRegistry = &WriterRegistry{
-	writers: make(map[string]writers.IWriter),
+	writers:    make(map[string]writers.IWriter),
+	generation: &writerGeneration{},
 }
 
 // Global registry for active function loggers to prevent duplicates.
@@ -121,6 +130,96 @@ func (wr *WriterRegistry) GetAllRegisteredWriters() map[string]writers.IWriter {
 	return writersCopy
 }
 
+// SwapWriters atomically registers the replacement writers and unregisters the removed names,
+// so every event is written either to the old set of writers or to the new one, never both.
+// It returns the writers that were replaced or removed once no event is still being written
+// to them; the caller is responsible for flushing and closing them.
+// It must not be called from a writer or a hook, as it would wait for itself.
+func (wr *WriterRegistry) SwapWriters(replacements map[string]writers.IWriter, remove []string) map[string]writers.IWriter {
+	wr.mu.Lock()
+	previous := make(map[string]writers.IWriter)
+	for name, writer := range replacements {
+		if existing, exists := wr.writers[name]; exists && existing != writer {
+			previous[name] = existing
+		}
+		wr.writers[name] = writer
+	}
+	for _, name := range remove {
+		if _, replaced := replacements[name]; replaced {
+			continue
+		}
+		if existing, exists := wr.writers[name]; exists {
+			previous[name] = existing
+			delete(wr.writers, name)
+		}
+	}
+	retired := wr.generation
+	wr.generation = &writerGeneration{}
+	wr.mu.Unlock()
+
+	retired.inflight.Wait()
+	return previous
+}
+
+// acquire returns the registered writers for writing one event, and the generation to release
+// with inflight.Done once the event has been written
+func (wr *WriterRegistry) acquire() ([]writers.IWriter, *writerGeneration) {
+	wr.mu.RLock()
+	defer wr.mu.RUnlock()
+
+	generation := wr.generation
+	generation.inflight.Add(1)
+
+	registered := make([]writers.IWriter, 0, len(wr.writers))
+	for _, writer := range wr.writers {
+		registered = append(registered, writer)
+	}
+	return registered, generation
+}
+
+// accepts reports whether any registered writer accepts level; writers that do not report a
+// level accept all
+func (wr *WriterRegistry) accepts(level log.Level) bool {
+	wr.mu.RLock()
+	defer wr.mu.RUnlock()
+
+	for _, writer := range wr.writers {
+		reporter, ok := writer.(writers.ILevelReporter)
+		if !ok || level >= reporter.GetLevel() {
+			return true
+		}
+	}
+	return false
+}
+
+// acquireWriters returns the writers of registry for writing one event, and a function to call
+// once the event has been written. A WriterRegistry is read under its swap generation; other
+// IWriterRegistry implementations are read with GetAllRegisteredWriters.
+func acquireWriters(registry IWriterRegistry) ([]writers.IWriter, func()) {
+	if wr, ok := registry.(*WriterRegistry); ok {
+		registered, generation := wr.acquire()
+		return registered, generation.inflight.Done
+	}
+
+	all := registry.GetAllRegisteredWriters()
+	registered := make([]writers.IWriter, 0, len(all))
+	for _, writer := range all {
+		registered = append(registered, writer)
+	}
+	return registered, func() {}
+}
+
+// registryAccepts reports whether any writer in registry accepts level
+func registryAccepts(registry IWriterRegistry, level log.Level) bool {
+	if wr, ok := registry.(*WriterRegistry); ok {
+		return wr.accepts(level)
+	}
+
+	registered, release := acquireWriters(registry)
+	defer release()
+	return writersAccept(registered, level)
+}
+
 // RegisterWriter registers a writer with the given name in the global registry
 func RegisterWriter(name string, writer writers.IWriter) {
 	globalWriterRegistry.RegisterWriter(name, writer)
@@ -153,6 +252,11 @@ func GetWriterCount() int {
 	return globalWriterRegistry.GetWriterCount()
 }
 
+// SwapWriters atomically replaces and removes writers in the global registry; see WriterRegistry.SwapWriters
+func SwapWriters(replacements map[string]writers.IWriter, remove []string) map[string]writers.IWriter {
+	return globalWriterRegistry.SwapWriters(replacements, remove)
+}
+
 // GetAllRegisteredWriters returns a copy of all registered writers
 func GetAllRegisteredWriters() map[string]writers.IWriter {
 	return globalWriterRegistry.GetAllRegisteredWriters()
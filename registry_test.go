@@ -108,3 +108,44 @@ func TestLoggerWithRegisteredWriters(t *testing.T) {
 	// Clean up
 	UnregisterWriter(WRITER_MEMORY)
 }
+
+func TestWriterRegistry_SwapWriters(t *testing.T) {
+	registry := NewWriterRegistry().(*WriterRegistry)
+	oldWriter := &captureWriter{}
+	newWriter := &captureWriter{}
+	removedWriter := &captureWriter{}
+	registry.RegisterWriter("main", oldWriter)
+	registry.RegisterWriter("extra", removedWriter)
+
+	// An event still being written holds the current generation
+	_, generation := registry.acquire()
+
+	swapped := make(chan map[string]writers.IWriter)
+	go func() {
+		swapped <- registry.SwapWriters(map[string]writers.IWriter{"main": newWriter}, []string{"extra"})
+	}()
+
+	select {
+	case <-swapped:
+		t.Fatal("SwapWriters should wait for events still being written to the old writers")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if registry.GetRegisteredWriter("main") != newWriter {
+		t.Error("New events should already use the replacement writer")
+	}
+	if registry.GetRegisteredWriter("extra") != nil {
+		t.Error("Removed writer should be unregistered")
+	}
+
+	generation.inflight.Done()
+
+	select {
+	case previous := <-swapped:
+		if len(previous) != 2 || previous["main"] != oldWriter || previous["extra"] != removedWriter {
+			t.Errorf("Expected the replaced and removed writers, got %v", previous)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("SwapWriters should return once the event has been written")
+	}
+}
@@ -36,5 +36,18 @@ func (e LogEntry) MarshalJSON() ([]byte, error) {
 		"function":      e.Function,
 		"fields":        e.Fields,
 	}
+	if e.TraceID != "" {
+		data["traceid"] = e.TraceID
+		data["spanid"] = e.SpanID
+	}
+	if e.Caller != nil {
+		data["caller"] = e.Caller
+	}
+	if len(e.ErrorChain) > 0 {
+		data["errorchain"] = e.ErrorChain
+	}
+	if len(e.Stack) > 0 {
+		data["stack"] = e.Stack
+	}
 	return json.Marshal(data)
 }
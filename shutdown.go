@@ -0,0 +1,234 @@
+package arbor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ternarybob/arbor/common"
+	"github.com/ternarybob/arbor/writers"
+)
+
+// contextBufferName identifies the deprecated singleton context buffer in a DrainError
+const contextBufferName = "context-buffer"
+
+// DrainError reports the writers and buffers that failed to drain during Flush or Shutdown.
+// Failures are keyed by registered writer name; channel buffers are reported as "channel:<name>".
+type DrainError struct {
+	Failures map[string]error
+}
+
+func (e *DrainError) Error() string {
+	names := make([]string, 0, len(e.Failures))
+	for name := range e.Failures {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s: %v", name, e.Failures[name])
+	}
+	return fmt.Sprintf("arbor: %d writer(s) failed to drain: %s", len(names), strings.Join(parts, "; "))
+}
+
+// Unwrap returns the individual failures, so errors.Is(err, context.DeadlineExceeded) works
+func (e *DrainError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failures))
+	for _, err := range e.Failures {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// drainer runs drain steps in order, recording failures and giving up once ctx is done
+type drainer struct {
+	ctx      context.Context
+	failures map[string]error
+}
+
+// run calls fn, waiting no longer than the context allows. A step that is still running
+// when the context ends is reported as failed and left to finish in the background.
+func (d *drainer) run(name string, fn func() error) {
+	if err := d.ctx.Err(); err != nil {
+		d.fail(name, err)
+		return
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- fn() }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			d.fail(name, err)
+		}
+	case <-d.ctx.Done():
+		d.fail(name, d.ctx.Err())
+	}
+}
+
+func (d *drainer) fail(name string, err error) {
+	if d.failures == nil {
+		d.failures = make(map[string]error)
+	}
+	d.failures[name] = err
+}
+
+func (d *drainer) err() error {
+	if len(d.failures) == 0 {
+		return nil
+	}
+	return &DrainError{Failures: d.failures}
+}
+
+// Flush reports outstanding sampler drops, then drains every registered writer, the channel buffers
+// and the deprecated context buffer, so events logged before the call reach their destination.
+// Writers are left open.
+// Returns a *DrainError naming the writers that failed or did not finish before ctx ended.
+func Flush(ctx context.Context) error {
+	return FlushRegistry(ctx, globalWriterRegistry)
+}
+
+// FlushRegistry drains the writers and channel buffers of registry as Flush does for the global
+// registry. Use it for registries passed to NewLoggerWithRegistry.
+func FlushRegistry(ctx context.Context, registry IWriterRegistry) error {
+	writers.ReportPendingSamples()
+
+	d := &drainer{ctx: ctx}
+	flushRegistered(d, registryOrGlobal(registry))
+	return d.err()
+}
+
+// Shutdown flushes and closes every registered writer and stops the channel buffers and the
+// deprecated context buffer. Writers feeding a log store are closed before the memory writers
+// that own the stores. Closed writers are unregistered, so later events are not written.
+// Returns a *DrainError naming the writers that failed or did not finish before ctx ended.
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+//	defer cancel()
+//	if err := arbor.Shutdown(ctx); err != nil {
+//		fmt.Fprintln(os.Stderr, err)
+//	}
+func Shutdown(ctx context.Context) error {
+	return ShutdownRegistry(ctx, globalWriterRegistry)
+}
+
+// ShutdownRegistry flushes, closes and unregisters the writers of registry and stops its channel
+// buffers as Shutdown does for the global registry. Use it for registries passed to
+// NewLoggerWithRegistry; other registries are not affected.
+func ShutdownRegistry(ctx context.Context, registry IWriterRegistry) error {
+	registry = registryOrGlobal(registry)
+	writers.ReportPendingSamples()
+
+	d := &drainer{ctx: ctx}
+	flushRegistered(d, registry)
+
+	for _, name := range shutdownOrder(registry.GetAllRegisteredWriters()) {
+		writer := registry.GetRegisteredWriter(name)
+		if writer == nil {
+			continue
+		}
+		registry.UnregisterWriter(name)
+		d.run(name, writer.Close)
+	}
+
+	buffers := channelBuffersOf(registry, true)
+	for _, name := range sortedBufferNames(buffers) {
+		buffer := buffers[name]
+		d.run("channel:"+name, func() error {
+			err := buffer.Flush()
+			buffer.Stop()
+			return err
+		})
+	}
+
+	if registry == globalWriterRegistry {
+		d.run(contextBufferName, func() error {
+			err := common.Flush()
+			common.Stop()
+			return err
+		})
+	}
+
+	return d.err()
+}
+
+// flushRegistered flushes the writers of registry, then the buffers they feed. The deprecated
+// context buffer belongs to the global registry.
+func flushRegistered(d *drainer, registry IWriterRegistry) {
+	for _, name := range shutdownOrder(registry.GetAllRegisteredWriters()) {
+		writer := registry.GetRegisteredWriter(name)
+		if flusher, ok := writer.(writers.IFlusher); ok {
+			d.run(name, flusher.Flush)
+		}
+	}
+
+	buffers := channelBuffersOf(registry, false)
+	for _, name := range sortedBufferNames(buffers) {
+		d.run("channel:"+name, buffers[name].Flush)
+	}
+
+	if registry == globalWriterRegistry {
+		d.run(contextBufferName, common.Flush)
+	}
+}
+
+// registryOrGlobal returns registry, or the global registry if it is nil
+func registryOrGlobal(registry IWriterRegistry) IWriterRegistry {
+	if registry == nil {
+		return globalWriterRegistry
+	}
+	return registry
+}
+
+// channelBuffersOf returns the channel buffers of registry by channel name, removing them from
+// the tracked buffers if remove is set
+func channelBuffersOf(registry IWriterRegistry, remove bool) map[string]*common.ChannelBuffer {
+	channelBuffersMux.Lock()
+	defer channelBuffersMux.Unlock()
+
+	buffers := make(map[string]*common.ChannelBuffer)
+	for key, buffer := range channelBuffers {
+		if key.registry != registry {
+			continue
+		}
+		buffers[key.name] = buffer
+		if remove {
+			delete(channelBuffers, key)
+		}
+	}
+	return buffers
+}
+
+// shutdownOrder returns writer names in drain order: writers that feed others first,
+// then memory writers, whose Close also closes the log store they own
+func shutdownOrder(registered map[string]writers.IWriter) []string {
+	names := make([]string, 0, len(registered))
+	for name := range registered {
+		names = append(names, name)
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		_, iMemory := registered[names[i]].(writers.IMemoryWriter)
+		_, jMemory := registered[names[j]].(writers.IMemoryWriter)
+		if iMemory != jMemory {
+			return jMemory
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// sortedBufferNames returns channel buffer names in a stable order
+func sortedBufferNames(buffers map[string]*common.ChannelBuffer) []string {
+	names := make([]string, 0, len(buffers))
+	for name := range buffers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
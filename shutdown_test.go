@@ -0,0 +1,175 @@
+package arbor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/phuslu/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ternarybob/arbor/models"
+	"github.com/ternarybob/arbor/writers"
+)
+
+// lifecycleWriter records Flush and Close calls; Flush blocks while block is open
+type lifecycleWriter struct {
+	name     string
+	block    chan struct{}
+	closeErr error
+	calls    *[]string
+	mu       *sync.Mutex
+}
+
+func (lw *lifecycleWriter) record(call string) {
+	lw.mu.Lock()
+	*lw.calls = append(*lw.calls, call+" "+lw.name)
+	lw.mu.Unlock()
+}
+
+func (lw *lifecycleWriter) Write(p []byte) (int, error)               { return len(p), nil }
+func (lw *lifecycleWriter) WithLevel(level log.Level) writers.IWriter { return lw }
+func (lw *lifecycleWriter) GetFilePath() string                       { return "" }
+
+func (lw *lifecycleWriter) Flush() error {
+	if lw.block != nil {
+		<-lw.block
+	}
+	lw.record("flush")
+	return nil
+}
+
+func (lw *lifecycleWriter) Close() error {
+	lw.record("close")
+	return lw.closeErr
+}
+
+// isolateRegistry empties the global registry for the test, restoring it afterwards
+func isolateRegistry(t *testing.T) {
+	saved := GetAllRegisteredWriters()
+	for name := range saved {
+		UnregisterWriter(name)
+	}
+	t.Cleanup(func() {
+		for name := range GetAllRegisteredWriters() {
+			UnregisterWriter(name)
+		}
+		for name, writer := range saved {
+			RegisterWriter(name, writer)
+		}
+	})
+}
+
+func TestFlush_DrainsChannelWriterAndBuffer(t *testing.T) {
+	isolateRegistry(t)
+
+	ch := make(chan []models.LogEvent, 1)
+	emitter := NewLogger()
+	emitter.SetChannelWithBuffer("flush-test", ch, 100, time.Hour)
+	t.Cleanup(func() { emitter.UnregisterChannel("flush-test") })
+
+	emitter.Info().Msg("one")
+	emitter.Info().Msg("two")
+
+	require.NoError(t, Flush(context.Background()))
+
+	select {
+	case batch := <-ch:
+		require.Len(t, batch, 2)
+		assert.Equal(t, "one", batch[0].Message)
+		assert.Equal(t, "two", batch[1].Message)
+	default:
+		t.Fatal("Expected Flush to deliver the buffered batch")
+	}
+
+	assert.NotNil(t, GetRegisteredWriter("flush-test"), "Flush must leave writers registered")
+}
+
+func TestShutdown_ClosesAndUnregistersWriters(t *testing.T) {
+	isolateRegistry(t)
+
+	var mu sync.Mutex
+	var calls []string
+	RegisterWriter("b", &lifecycleWriter{name: "b", calls: &calls, mu: &mu})
+	RegisterWriter("a", &lifecycleWriter{name: "a", calls: &calls, mu: &mu})
+
+	ch := make(chan []models.LogEvent, 1)
+	emitter := NewLogger()
+	emitter.SetChannelWithBuffer("shutdown-test", ch, 100, time.Hour)
+	emitter.Info().Msg("pending")
+
+	require.NoError(t, Shutdown(context.Background()))
+
+	assert.Equal(t, []string{"flush a", "flush b", "close a", "close b"}, calls)
+	assert.Zero(t, GetWriterCount())
+
+	select {
+	case batch := <-ch:
+		require.Len(t, batch, 1)
+		assert.Equal(t, "pending", batch[0].Message)
+	default:
+		t.Fatal("Expected Shutdown to deliver the buffered batch")
+	}
+
+	assert.Nil(t, GetChannel("shutdown-test"), "the channel buffer is no longer tracked")
+}
+
+func TestShutdown_ReportsFailures(t *testing.T) {
+	isolateRegistry(t)
+
+	var mu sync.Mutex
+	var calls []string
+	closeErr := errors.New("disk full")
+	RegisterWriter("broken", &lifecycleWriter{name: "broken", closeErr: closeErr, calls: &calls, mu: &mu})
+	RegisterWriter("healthy", &lifecycleWriter{name: "healthy", calls: &calls, mu: &mu})
+
+	err := Shutdown(context.Background())
+
+	var drainErr *DrainError
+	require.ErrorAs(t, err, &drainErr)
+	assert.Len(t, drainErr.Failures, 1)
+	assert.ErrorIs(t, err, closeErr)
+	assert.Contains(t, err.Error(), "broken: disk full")
+	assert.Contains(t, calls, "close healthy", "a failing writer must not stop the others from closing")
+}
+
+func TestShutdown_RespectsDeadline(t *testing.T) {
+	isolateRegistry(t)
+
+	var mu sync.Mutex
+	var calls []string
+	block := make(chan struct{})
+	t.Cleanup(func() { close(block) })
+	RegisterWriter("stuck", &lifecycleWriter{name: "stuck", block: block, calls: &calls, mu: &mu})
+	RegisterWriter("waiting", &lifecycleWriter{name: "waiting", calls: &calls, mu: &mu})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	started := time.Now()
+	err := Shutdown(ctx)
+	assert.Less(t, time.Since(started), time.Second, "Shutdown must return once the context ends")
+
+	var drainErr *DrainError
+	require.ErrorAs(t, err, &drainErr)
+	assert.ErrorIs(t, drainErr.Failures["stuck"], context.DeadlineExceeded)
+	assert.ErrorIs(t, drainErr.Failures["waiting"], context.DeadlineExceeded, "steps after the deadline are reported, not run")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestShutdownOrder_MemoryWritersLast(t *testing.T) {
+	memWriter := writers.MemoryWriter(models.WriterConfiguration{Type: models.LogWriterTypeMemory})
+	defer memWriter.Close()
+
+	var mu sync.Mutex
+	var calls []string
+	order := shutdownOrder(map[string]writers.IWriter{
+		"a-memory": memWriter,
+		"logstore": &lifecycleWriter{name: "logstore", calls: &calls, mu: &mu},
+		"console":  &lifecycleWriter{name: "console", calls: &calls, mu: &mu},
+	})
+
+	assert.Equal(t, []string{"console", "logstore", "a-memory"}, order)
+}
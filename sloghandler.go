@@ -0,0 +1,267 @@
+package arbor
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/phuslu/log"
+	"github.com/ternarybob/arbor/models"
+)
+
+// Additional slog levels for arbor levels that have no slog equivalent.
+// slog.LevelDebug, slog.LevelInfo, slog.LevelWarn and slog.LevelError map directly.
+const (
+	SlogLevelTrace slog.Level = slog.LevelDebug - 4
+	SlogLevelFatal slog.Level = slog.LevelError + 4
+	SlogLevelPanic slog.Level = slog.LevelError + 8
+)
+
+// SlogHandlerOptions configures the slog handler returned by NewSlogHandler.
+type SlogHandlerOptions struct {
+	// Level is the minimum slog level handled. If nil, all records are passed
+	// through and filtering is left to the registered writers.
+	Level slog.Leveler
+}
+
+// slogHandler implements slog.Handler by converting slog records into
+// models.LogEvent and sending them through the arbor logger's writers.
+type slogHandler struct {
+	logger *logger // nil when writing through target
+	target ILogger // An ILogger other than arbor's own, written to through its public methods
+	level  slog.Leveler
+	attrs  []groupedAttrs // attributes added via WithAttrs, with the groups open at the time
+	groups []string       // groups opened via WithGroup
+}
+
+// groupedAttrs holds attributes together with the group path they were added under.
+type groupedAttrs struct {
+	groups []string
+	attrs  []slog.Attr
+}
+
+// Ensure slogHandler implements slog.Handler
+var _ slog.Handler = (*slogHandler)(nil)
+
+// NewSlogHandler creates a slog.Handler that writes through the given arbor logger.
+// Correlation ID and prefix are taken from the logger's context, and may be overridden
+// per record by a "correlationid" attribute or by the record's context (see NewContext
+// and ContextWithCorrelationID).
+// If l is nil, the default logger is used. Other ILogger implementations are written to through
+// their public methods, at error level for records above error, without the record's caller.
+//
+// Example:
+//
+//	slogger := slog.New(arbor.NewSlogHandler(arbor.Logger().WithPrefix("api"), nil))
+//	slogger.Info("request handled", "status", 200)
+func NewSlogHandler(l ILogger, opts *SlogHandlerOptions) slog.Handler {
+	if l == nil {
+		l = Logger()
+	}
+
+	handler := &slogHandler{}
+	if arborLogger, ok := l.(*logger); ok {
+		handler.logger = arborLogger
+	} else {
+		handler.target = l
+	}
+	if opts != nil {
+		handler.level = opts.Level
+	}
+
+	return handler
+}
+
+// Enabled reports whether the handler handles records at the given level.
+// Both the handler's Level option and the arbor logger's own level must allow it.
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	if h.level != nil && level < h.level.Level() {
+		return false
+	}
+	if h.logger == nil {
+		return true // The target logger applies its own level
+	}
+	return h.logger.enabled(SlogLevelToLogLevel(level))
+}
+
+// Handle converts the record to a models.LogEvent and writes it to the logger's writers.
+func (h *slogHandler) Handle(ctx context.Context, record slog.Record) error {
+	// Start with the logger's context, then let the context and attributes override it
+	level, fields := SlogLevelToLogLevel(record.Level), make(map[string]interface{})
+	var logEvent *models.LogEvent
+	if h.logger != nil {
+		logEvent = h.logger.buildEvent(ctx, level, record.Message, fields)
+	} else {
+		// The target logger adds its own context and fields
+		logEvent = &models.LogEvent{Level: level, Message: record.Message, Fields: fields}
+		applyContext(ctx, logEvent)
+	}
+	if !record.Time.IsZero() {
+		logEvent.Timestamp = record.Time
+	}
+
+	for _, ga := range h.attrs {
+		for _, attr := range ga.attrs {
+			h.addAttr(logEvent, ga.groups, attr)
+		}
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		h.addAttr(logEvent, h.groups, attr)
+		return true
+	})
+
+	if h.logger == nil {
+		writeThrough(h.target, logEvent)
+		return nil
+	}
+
+	if !h.logger.noCaller {
+		if caller := callerFromPC(record.PC); caller != nil {
+			logEvent.Caller = caller
+			logEvent.Function = caller.Function
+		}
+	}
+
+	h.logger.writeEvent(logEvent)
+	return nil
+}
+
+// WithAttrs returns a new handler whose records include the given attributes.
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	clone := h.clone()
+	clone.attrs = append(clone.attrs, groupedAttrs{
+		groups: h.groups,
+		attrs:  append([]slog.Attr(nil), attrs...),
+	})
+	return clone
+}
+
+// WithGroup returns a new handler that nests subsequent attributes under the named group.
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	clone := h.clone()
+	clone.groups = append(append([]string(nil), h.groups...), name)
+	return clone
+}
+
+func (h *slogHandler) clone() *slogHandler {
+	return &slogHandler{
+		logger: h.logger,
+		target: h.target,
+		level:  h.level,
+		attrs:  append([]groupedAttrs(nil), h.attrs...),
+		groups: h.groups,
+	}
+}
+
+// addAttr adds a single attribute to the event under the given group path.
+// Top-level "correlationid" and "error"/"err" attributes populate the dedicated LogEvent fields.
+func (h *slogHandler) addAttr(logEvent *models.LogEvent, groups []string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+
+	if len(groups) == 0 {
+		switch attr.Key {
+		case CORRELATION_ID_KEY:
+			if attr.Value.Kind() == slog.KindString {
+				logEvent.CorrelationID = attr.Value.String()
+				return
+			}
+		case "error", "err":
+			if err, ok := attr.Value.Any().(error); ok {
+				logEvent.Error = err.Error()
+				logEvent.ErrorChain = errorChain(err)
+				return
+			}
+		}
+	}
+
+	target := logEvent.Fields
+	for _, group := range groups {
+		target = subGroup(target, group)
+	}
+	setSlogValue(target, attr.Key, attr.Value)
+}
+
+// subGroup returns the nested map stored under key, creating it if needed.
+func subGroup(fields map[string]interface{}, key string) map[string]interface{} {
+	if nested, ok := fields[key].(map[string]interface{}); ok {
+		return nested
+	}
+	nested := make(map[string]interface{})
+	fields[key] = nested
+	return nested
+}
+
+// setSlogValue stores a resolved slog value in fields, expanding groups into nested maps.
+func setSlogValue(fields map[string]interface{}, key string, value slog.Value) {
+	switch value.Kind() {
+	case slog.KindGroup:
+		groupAttrs := value.Group()
+		if len(groupAttrs) == 0 {
+			return
+		}
+		// Groups with an empty key are inlined into the parent
+		target := fields
+		if key != "" {
+			target = subGroup(fields, key)
+		}
+		for _, attr := range groupAttrs {
+			attr.Value = attr.Value.Resolve()
+			if attr.Equal(slog.Attr{}) {
+				continue
+			}
+			setSlogValue(target, attr.Key, attr.Value)
+		}
+	case slog.KindString:
+		fields[key] = value.String()
+	case slog.KindInt64:
+		fields[key] = value.Int64()
+	case slog.KindUint64:
+		fields[key] = value.Uint64()
+	case slog.KindFloat64:
+		fields[key] = value.Float64()
+	case slog.KindBool:
+		fields[key] = value.Bool()
+	case slog.KindDuration:
+		// Durations are stored as strings, matching logEvent.Dur
+		fields[key] = value.Duration().String()
+	case slog.KindTime:
+		fields[key] = value.Time()
+	default:
+		if err, ok := value.Any().(error); ok {
+			fields[key] = err.Error()
+			return
+		}
+		fields[key] = value.Any()
+	}
+}
+
+// SlogLevelToLogLevel maps a slog level onto the nearest arbor level.
+// Levels between the standard slog levels round down, e.g. slog.LevelInfo+2 maps to info.
+func SlogLevelToLogLevel(level slog.Level) log.Level {
+	switch {
+	case level >= SlogLevelPanic:
+		return log.PanicLevel
+	case level >= SlogLevelFatal:
+		return log.FatalLevel
+	case level >= slog.LevelError:
+		return log.ErrorLevel
+	case level >= slog.LevelWarn:
+		return log.WarnLevel
+	case level >= slog.LevelInfo:
+		return log.InfoLevel
+	case level >= slog.LevelDebug:
+		return log.DebugLevel
+	default:
+		return log.TraceLevel
+	}
+}
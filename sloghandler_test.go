@@ -0,0 +1,188 @@
+package arbor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"testing"
+
+	"github.com/phuslu/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ternarybob/arbor/models"
+	"github.com/ternarybob/arbor/writers"
+)
+
+// captureWriter is a test writer that records every event it receives
+type captureWriter struct {
+	mu     sync.Mutex
+	events []models.LogEvent
+}
+
+func (cw *captureWriter) WithLevel(level log.Level) writers.IWriter { return cw }
+func (cw *captureWriter) GetFilePath() string                       { return "" }
+func (cw *captureWriter) Close() error                              { return nil }
+
+func (cw *captureWriter) Write(p []byte) (int, error) {
+	var event models.LogEvent
+	if err := json.Unmarshal(p, &event); err != nil {
+		return 0, err
+	}
+	cw.mu.Lock()
+	cw.events = append(cw.events, event)
+	cw.mu.Unlock()
+	return len(p), nil
+}
+
+func (cw *captureWriter) Events() []models.LogEvent {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+	return append([]models.LogEvent(nil), cw.events...)
+}
+
+func newCaptureLogger() (ILogger, *captureWriter) {
+	capture := &captureWriter{}
+	return NewLogger().WithWriters([]writers.IWriter{capture}), capture
+}
+
+// wrappedLogger is an ILogger implemented outside the package, decorating arbor's logger
+type wrappedLogger struct {
+	ILogger
+}
+
+func TestSlogHandler_BasicRecord(t *testing.T) {
+	arborLogger, capture := newCaptureLogger()
+	slogger := slog.New(NewSlogHandler(arborLogger.WithPrefix("api").WithCorrelationId("corr-1"), nil))
+
+	slogger.Info("request handled", "status", 200, "path", "/health")
+
+	events := capture.Events()
+	require.Len(t, events, 1)
+	event := events[0]
+	assert.Equal(t, log.InfoLevel, event.Level)
+	assert.Equal(t, "request handled", event.Message)
+	assert.Equal(t, "api", event.Prefix)
+	assert.Equal(t, "corr-1", event.CorrelationID)
+	assert.Equal(t, "/health", event.Fields["path"])
+	assert.EqualValues(t, 200, event.Fields["status"])
+	assert.Contains(t, event.Function, "TestSlogHandler_BasicRecord")
+	require.NotNil(t, event.Caller)
+	assert.Contains(t, event.Caller.File, "sloghandler_test.go")
+}
+
+func TestSlogHandler_WithAttrsAndGroups(t *testing.T) {
+	arborLogger, capture := newCaptureLogger()
+	slogger := slog.New(NewSlogHandler(arborLogger, nil)).
+		With("service", "billing").
+		WithGroup("req").
+		With("id", "r-1")
+
+	slogger.Warn("slow", slog.Group("timing", slog.Int("ms", 1500)))
+
+	events := capture.Events()
+	require.Len(t, events, 1)
+	event := events[0]
+	assert.Equal(t, log.WarnLevel, event.Level)
+	assert.Equal(t, "billing", event.Fields["service"])
+
+	req, ok := event.Fields["req"].(map[string]interface{})
+	require.True(t, ok, "req group should be a nested object")
+	assert.Equal(t, "r-1", req["id"])
+
+	timing, ok := req["timing"].(map[string]interface{})
+	require.True(t, ok, "timing group should be nested under req")
+	assert.EqualValues(t, 1500, timing["ms"])
+}
+
+func TestSlogHandler_CorrelationIDSources(t *testing.T) {
+	arborLogger, capture := newCaptureLogger()
+	slogger := slog.New(NewSlogHandler(arborLogger.WithCorrelationId("from-logger"), nil))
+
+	slogger.Info("logger")
+	slogger.InfoContext(ContextWithCorrelationID(context.Background(), "from-context"), "context")
+	slogger.InfoContext(ContextWithCorrelationID(context.Background(), "from-context"), "attr", CORRELATION_ID_KEY, "from-attr")
+
+	events := capture.Events()
+	require.Len(t, events, 3)
+	assert.Equal(t, "from-logger", events[0].CorrelationID)
+	assert.Equal(t, "from-context", events[1].CorrelationID)
+	assert.Equal(t, "from-attr", events[2].CorrelationID)
+	assert.NotContains(t, events[2].Fields, CORRELATION_ID_KEY)
+}
+
+func TestSlogHandler_ErrorAttr(t *testing.T) {
+	arborLogger, capture := newCaptureLogger()
+	slogger := slog.New(NewSlogHandler(arborLogger, nil))
+
+	slogger.Error("failed", "error", errors.New("boom"))
+
+	events := capture.Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, log.ErrorLevel, events[0].Level)
+	assert.Equal(t, "boom", events[0].Error)
+}
+
+func TestSlogHandler_Enabled(t *testing.T) {
+	arborLogger, capture := newCaptureLogger()
+	slogger := slog.New(NewSlogHandler(arborLogger, &SlogHandlerOptions{Level: slog.LevelWarn}))
+
+	slogger.Debug("dropped")
+	slogger.Info("dropped")
+	slogger.Warn("kept")
+
+	events := capture.Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, "kept", events[0].Message)
+}
+
+func TestSlogHandler_OtherILogger(t *testing.T) {
+	arborLogger, capture := newCaptureLogger()
+	slogger := slog.New(NewSlogHandler(wrappedLogger{arborLogger.WithPrefix("api").WithLevel(InfoLevel)}, nil))
+
+	slogger.Debug("below the logger's level")
+	slogger.With("service", "billing").WithGroup("req").Info("handled", "status", 200)
+	slogger.Error("failed", "error", fmt.Errorf("charge: %w", errors.New("boom")), CORRELATION_ID_KEY, "corr-9")
+	slogger.Log(context.Background(), SlogLevelFatal, "fatal record")
+
+	events := capture.Events()
+	require.Len(t, events, 3, "records are written through the given logger, not a replacement")
+
+	assert.Equal(t, "handled", events[0].Message)
+	assert.Equal(t, "api", events[0].Prefix)
+	assert.Equal(t, "billing", events[0].Fields["service"])
+	assert.Equal(t, map[string]interface{}{"status": float64(200)}, events[0].Fields["req"])
+
+	assert.Equal(t, "charge: boom", events[1].Error)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"message": "charge: boom", "type": "*fmt.wrapError"},
+		map[string]interface{}{"message": "boom", "type": "*errors.errorString"},
+	}, events[1].Fields["errorchain"], "the chain of the wrapped error is kept as a field")
+	assert.Equal(t, "corr-9", events[1].CorrelationID)
+
+	assert.Equal(t, log.ErrorLevel, events[2].Level, "records above error are written at error level")
+}
+
+func TestSlogLevelToLogLevel(t *testing.T) {
+	testCases := []struct {
+		slogLevel slog.Level
+		expected  log.Level
+	}{
+		{SlogLevelTrace, log.TraceLevel},
+		{slog.LevelDebug, log.DebugLevel},
+		{slog.LevelInfo, log.InfoLevel},
+		{slog.LevelInfo + 2, log.InfoLevel},
+		{slog.LevelWarn, log.WarnLevel},
+		{slog.LevelError, log.ErrorLevel},
+		{SlogLevelFatal, log.FatalLevel},
+		{SlogLevelPanic, log.PanicLevel},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.slogLevel.String(), func(t *testing.T) {
+			assert.Equal(t, tc.expected, SlogLevelToLogLevel(tc.slogLevel))
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package arbor
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/phuslu/log"
+)
+
+// Field keys written by spans
+const (
+	SPAN_ID_KEY          = "span_id"
+	PARENT_SPAN_ID_KEY   = "parent_span_id"
+	SPAN_OPERATION_KEY   = "operation"
+	SPAN_STATUS_KEY      = "status"
+	SPAN_DURATION_KEY    = "duration"
+	SPAN_DURATION_MS_KEY = "duration_ms"
+)
+
+// Span outcomes recorded in the status field of the end event
+const (
+	SpanStatusOK    = "ok"
+	SpanStatusError = "error"
+)
+
+// ISpan times an operation, logging an event when it starts and another, with its duration
+// and outcome, when it ends
+type ISpan interface {
+	// ID returns the span's ID, a 16 character hex string
+	ID() string
+
+	// ParentID returns the ID of the span this span was started from, or "" for a root span
+	ParentID() string
+
+	// Logger returns a logger whose events carry the span's ID and correlation ID
+	Logger() ILogger
+
+	// Start begins a child span of this span
+	Start(operation string) ISpan
+
+	// WithThreshold raises the level of the end event to level when the span lasts at least after.
+	// Levels above error are treated as error, so a slow span never exits or panics.
+	WithThreshold(after time.Duration, level LogLevel) ISpan
+
+	// Elapsed returns the time since the span started
+	Elapsed() time.Duration
+
+	// End logs the end event, with status "error" and the error when err is not nil, and
+	// returns the span's duration. Only the first call logs.
+	End(err error) time.Duration
+}
+
+// spanThreshold escalates the end event of a slow span
+type spanThreshold struct {
+	after time.Duration
+	level log.Level
+}
+
+// span implements ISpan
+type span struct {
+	logger    *logger // Carries the span's fields and correlation ID
+	operation string
+	id        string
+	parentID  string
+	started   time.Time
+
+	mu         sync.Mutex
+	thresholds []spanThreshold
+	ended      atomic.Bool
+}
+
+// Start begins a span timing operation. The span's start and end events are logged at info
+// level; the end event at error level if it failed, or at the level of the slowest threshold
+// it exceeded. Both events carry the operation, the span and parent span IDs and the
+// correlation ID; a logger without a correlation ID uses the root span's ID, so
+// GetMemoryLogsForCorrelation(span.ID()) returns the span's events.
+//
+// Example:
+//
+//	span := logger.Start("import").WithThreshold(2*time.Second, arbor.WarnLevel)
+//	err := importFile(span.Logger(), path)
+//	span.End(err) // "import completed in 1.2s" or "import failed after 300ms"
+func (l *logger) Start(operation string) ISpan {
+	return startSpan(l, operation, "")
+}
+
+func (s *span) ID() string {
+	return s.id
+}
+
+func (s *span) ParentID() string {
+	return s.parentID
+}
+
+func (s *span) Logger() ILogger {
+	return s.logger.fork()
+}
+
+func (s *span) Start(operation string) ISpan {
+	return startSpan(s.logger, operation, s.id)
+}
+
+func (s *span) WithThreshold(after time.Duration, level LogLevel) ISpan {
+	threshold := spanThreshold{after: after, level: level.ToLogLevel()}
+	if threshold.level > log.ErrorLevel {
+		threshold.level = log.ErrorLevel
+	}
+
+	s.mu.Lock()
+	s.thresholds = append(s.thresholds, threshold)
+	s.mu.Unlock()
+	return s
+}
+
+func (s *span) Elapsed() time.Duration {
+	return time.Since(s.started)
+}
+
+func (s *span) End(err error) time.Duration {
+	elapsed := time.Since(s.started)
+	if s.ended.Swap(true) {
+		return elapsed
+	}
+
+	level := log.InfoLevel
+	status := SpanStatusOK
+	message := fmt.Sprintf("%s completed in %s", s.operation, elapsed)
+	if err != nil {
+		level = log.ErrorLevel
+		status = SpanStatusError
+		message = fmt.Sprintf("%s failed after %s", s.operation, elapsed)
+	}
+
+	s.mu.Lock()
+	for _, threshold := range s.thresholds {
+		if elapsed >= threshold.after && threshold.level > level {
+			level = threshold.level
+		}
+	}
+	s.mu.Unlock()
+
+	// Report the caller of End rather than End itself
+	events := s.logger.fork()
+	events.callerSkip++
+
+	events.newEvent(level).
+		Str(SPAN_STATUS_KEY, status).
+		Dur(SPAN_DURATION_KEY, elapsed).
+		Float64(SPAN_DURATION_MS_KEY, float64(elapsed)/float64(time.Millisecond)).
+		Err(err).
+		Msg(message)
+
+	return elapsed
+}
+
+// startSpan creates a span on a fork of parent and logs its start event
+func startSpan(parent *logger, operation, parentID string) *span {
+	s := &span{
+		logger:    parent.fork(),
+		operation: operation,
+		id:        newSpanID(),
+		parentID:  parentID,
+	}
+
+	if _, exists := s.logger.contextData[CORRELATION_ID_KEY]; !exists {
+		s.logger.setContext(CORRELATION_ID_KEY, s.id)
+	}
+	s.logger.setField(SPAN_OPERATION_KEY, operation)
+	s.logger.setField(SPAN_ID_KEY, s.id)
+	if parentID != "" {
+		s.logger.setField(PARENT_SPAN_ID_KEY, parentID)
+	}
+
+	// Report the caller of Start, skipping startSpan and Start
+	events := s.logger.fork()
+	events.callerSkip += 2
+	events.Info().Msg(operation + " started")
+
+	s.started = time.Now()
+	return s
+}
+
+// newSpanID returns a random 8 byte ID as 16 hex characters
+func newSpanID() string {
+	var id [8]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		// crypto/rand does not fail on supported platforms; fall back to the clock
+		return fmt.Sprintf("%016x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(id[:])
+}
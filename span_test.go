@@ -0,0 +1,146 @@
+package arbor
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/phuslu/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ternarybob/arbor/levels"
+	"github.com/ternarybob/arbor/models"
+)
+
+func TestSpan_StartAndEnd(t *testing.T) {
+	emitter, capture := newCaptureLogger()
+
+	span := emitter.Start("import")
+	elapsed := span.End(nil)
+
+	events := capture.Events()
+	require.Len(t, events, 2)
+	started, ended := events[0], events[1]
+
+	assert.Equal(t, log.InfoLevel, started.Level)
+	assert.Equal(t, "import started", started.Message)
+	assert.Equal(t, "import", started.Fields[SPAN_OPERATION_KEY])
+	assert.Equal(t, span.ID(), started.Fields[SPAN_ID_KEY])
+	assert.NotContains(t, started.Fields, PARENT_SPAN_ID_KEY)
+	assert.Equal(t, span.ID(), started.CorrelationID, "a logger without a correlation ID uses the span ID")
+	require.NotNil(t, started.Caller)
+	assert.Equal(t, "span_test.go", filepath.Base(started.Caller.File))
+
+	assert.Equal(t, log.InfoLevel, ended.Level)
+	assert.True(t, strings.HasPrefix(ended.Message, "import completed in "), ended.Message)
+	assert.Equal(t, SpanStatusOK, ended.Fields[SPAN_STATUS_KEY])
+	assert.Equal(t, elapsed.String(), ended.Fields[SPAN_DURATION_KEY])
+	assert.Contains(t, ended.Fields, SPAN_DURATION_MS_KEY)
+	assert.Equal(t, span.ID(), ended.Fields[SPAN_ID_KEY])
+	require.NotNil(t, ended.Caller)
+	assert.Equal(t, "span_test.go", filepath.Base(ended.Caller.File))
+
+	span.End(errors.New("late"))
+	assert.Len(t, capture.Events(), 2, "only the first End logs")
+}
+
+func TestSpan_Failure(t *testing.T) {
+	emitter, capture := newCaptureLogger()
+
+	emitter.Start("upload").WithThreshold(0, WarnLevel).End(errors.New("connection reset"))
+
+	ended := capture.Events()[1]
+	assert.Equal(t, log.ErrorLevel, ended.Level, "thresholds never lower the level of a failure")
+	assert.True(t, strings.HasPrefix(ended.Message, "upload failed after "), ended.Message)
+	assert.Equal(t, SpanStatusError, ended.Fields[SPAN_STATUS_KEY])
+	assert.Equal(t, "connection reset", ended.Error)
+}
+
+func TestSpan_Thresholds(t *testing.T) {
+	emitter, capture := newCaptureLogger()
+
+	span := emitter.Start("query").
+		WithThreshold(time.Millisecond, WarnLevel).
+		WithThreshold(time.Hour, ErrorLevel)
+	time.Sleep(2 * time.Millisecond)
+	span.End(nil)
+
+	emitter.Start("fast").WithThreshold(time.Hour, WarnLevel).End(nil)
+
+	events := capture.Events()
+	require.Len(t, events, 4)
+	assert.Equal(t, log.WarnLevel, events[1].Level)
+	assert.Equal(t, SpanStatusOK, events[1].Fields[SPAN_STATUS_KEY])
+	assert.Equal(t, log.InfoLevel, events[3].Level)
+}
+
+func TestSpan_ThresholdCappedAtError(t *testing.T) {
+	codes := captureExit(t)
+	emitter, capture := newCaptureLogger()
+
+	assert.NotPanics(t, func() {
+		emitter.Start("slow").WithThreshold(0, FatalLevel).End(nil)
+		emitter.Start("slower").WithThreshold(0, PanicLevel).End(nil)
+	})
+
+	events := capture.Events()
+	require.Len(t, events, 4)
+	assert.Equal(t, log.ErrorLevel, events[1].Level)
+	assert.Equal(t, log.ErrorLevel, events[3].Level)
+	assert.Empty(t, *codes, "a slow span never exits")
+}
+
+func TestSpan_Children(t *testing.T) {
+	emitter, capture := newCaptureLogger()
+
+	root := emitter.WithCorrelationId("req-1").Start("request")
+	child := root.Start("db")
+	child.Logger().Info().Msg("inside")
+	child.End(nil)
+	root.End(nil)
+
+	assert.Empty(t, root.ParentID())
+	assert.Equal(t, root.ID(), child.ParentID())
+	assert.NotEqual(t, root.ID(), child.ID())
+
+	events := capture.Events()
+	require.Len(t, events, 5)
+	for _, event := range events {
+		assert.Equal(t, "req-1", event.CorrelationID, "spans keep the logger's correlation ID")
+	}
+
+	inside := events[2]
+	assert.Equal(t, "inside", inside.Message)
+	assert.Equal(t, child.ID(), inside.Fields[SPAN_ID_KEY])
+	assert.Equal(t, root.ID(), inside.Fields[PARENT_SPAN_ID_KEY])
+	assert.Equal(t, "db", inside.Fields[SPAN_OPERATION_KEY])
+	assert.Equal(t, root.ID(), events[4].Fields[SPAN_ID_KEY])
+}
+
+func TestSpan_MemoryLogs(t *testing.T) {
+	isolateRegistry(t)
+	// A memory writer at info, the default writer level, receives both events
+	emitter := NewLogger().WithMemoryWriter(models.WriterConfiguration{
+		Type:  models.LogWriterTypeMemory,
+		Level: levels.InfoLevel,
+	})
+	t.Cleanup(func() { GetRegisteredWriter(WRITER_MEMORY).Close() })
+
+	span := emitter.Start("report")
+	span.End(nil)
+
+	var entries map[string]string
+	require.Eventually(t, func() bool {
+		entries, _ = emitter.GetMemoryLogsForCorrelation(span.ID())
+		return len(entries) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	var messages []string
+	for _, entry := range entries {
+		messages = append(messages, entry)
+	}
+	assert.Contains(t, strings.Join(messages, "\n"), "report started")
+	assert.Contains(t, strings.Join(messages, "\n"), "report completed in ")
+}
@@ -0,0 +1,227 @@
+package arbor
+
+import (
+	"bytes"
+	"io"
+	stdlog "log"
+	"strings"
+	"sync"
+
+	"github.com/phuslu/log"
+	"github.com/ternarybob/arbor/models"
+)
+
+// maxLineLength bounds the bytes a line writer buffers while waiting for a newline;
+// longer lines are written in pieces of this size
+const maxLineLength = 64 * 1024
+
+// LineWriterOptions configures NewLineWriter
+type LineWriterOptions struct {
+	// Level of the events; InfoLevel if zero. With DetectLevel it applies to lines without a level marker.
+	Level LogLevel
+
+	// Prefix of the events, replacing the logger's prefix when set
+	Prefix string
+
+	// DetectLevel takes the level from a marker at the start of the line, such as "ERROR",
+	// "[warn]", "debug:" or "level=info", and removes the marker from the message
+	DetectLevel bool
+}
+
+// lineWriter frames the bytes written to it into lines and writes each line as an event
+type lineWriter struct {
+	logger  ILogger // nil writes each line to the default logger at the time
+	level   log.Level
+	prefix  string
+	detect  bool
+	mu      sync.Mutex
+	pending []byte
+}
+
+// NewLineWriter returns an io.WriteCloser for libraries that log to an io.Writer. Each line
+// written becomes an event of l (the default logger when nil, looked up for every line) with
+// the level and prefix of opts. A partial line is kept until its newline arrives or the writer
+// is closed; empty lines are ignored. Lines pass through the logger's hooks, redaction and
+// sampling like its own events. Fatal and panic lines are logged without exiting or panicking,
+// as the library that wrote them decides what happens next. An ILogger implemented outside arbor
+// is written to through its public methods, with fatal and panic lines at error level.
+//
+// Example:
+//
+//	w := arbor.NewLineWriter(arbor.Logger().WithPrefix("kafka"), &arbor.LineWriterOptions{DetectLevel: true})
+//	defer w.Close()
+//	client.SetLogOutput(w)
+func NewLineWriter(l ILogger, opts *LineWriterOptions) io.WriteCloser {
+	lw := &lineWriter{logger: l, level: log.InfoLevel}
+
+	if opts != nil {
+		if opts.Level != Disabled {
+			lw.level = opts.Level.ToLogLevel()
+		}
+		lw.prefix = opts.Prefix
+		lw.detect = opts.DetectLevel
+	}
+
+	return lw
+}
+
+// StdLogger returns a standard library *log.Logger whose output is written to the default
+// logger at level, one event per call, for libraries that accept a *log.Logger.
+//
+// Example:
+//
+//	server := &http.Server{ErrorLog: arbor.StdLogger(arbor.ErrorLevel)}
+func StdLogger(level LogLevel) *stdlog.Logger {
+	return stdlog.New(NewLineWriter(nil, &LineWriterOptions{Level: level}), "", 0)
+}
+
+// RedirectStdLog sends the output of the standard library's log package to the default logger,
+// at info level unless the message starts with a level marker (see LineWriterOptions.DetectLevel).
+// The standard logger's prefix and flags are cleared, as events carry their own timestamp.
+// It returns a function that restores the previous output, prefix and flags.
+//
+// Example:
+//
+//	restore := arbor.RedirectStdLog()
+//	defer restore()
+//	log.Printf("[WARN] cache miss for %s", key) // a warn event "cache miss for ..."
+func RedirectStdLog() (restore func()) {
+	output, prefix, flags := stdlog.Writer(), stdlog.Prefix(), stdlog.Flags()
+
+	stdlog.SetOutput(NewLineWriter(nil, &LineWriterOptions{DetectLevel: true}))
+	stdlog.SetPrefix("")
+	stdlog.SetFlags(0)
+
+	return func() {
+		stdlog.SetOutput(output)
+		stdlog.SetPrefix(prefix)
+		stdlog.SetFlags(flags)
+	}
+}
+
+// Write writes every complete line in p as an event, keeping a trailing partial line
+func (lw *lineWriter) Write(p []byte) (int, error) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+
+	data := p
+	for len(data) > 0 {
+		newline := bytes.IndexByte(data, '\n')
+		if newline < 0 {
+			lw.pending = append(lw.pending, data...)
+			for len(lw.pending) >= maxLineLength {
+				lw.writeLine(lw.pending[:maxLineLength])
+				lw.pending = append(lw.pending[:0], lw.pending[maxLineLength:]...)
+			}
+			break
+		}
+
+		if len(lw.pending) > 0 {
+			lw.pending = append(lw.pending, data[:newline]...)
+			lw.writeLine(lw.pending)
+			lw.pending = lw.pending[:0]
+		} else {
+			lw.writeLine(data[:newline])
+		}
+		data = data[newline+1:]
+	}
+
+	return len(p), nil
+}
+
+// Close writes a pending partial line
+func (lw *lineWriter) Close() error {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+
+	if len(lw.pending) > 0 {
+		lw.writeLine(lw.pending)
+		lw.pending = nil
+	}
+	return nil
+}
+
+// writeLine writes one line as an event
+func (lw *lineWriter) writeLine(line []byte) {
+	message := strings.TrimRight(string(line), "\r\n\t ")
+	if strings.TrimSpace(message) == "" {
+		return
+	}
+
+	level := lw.level
+	if lw.detect {
+		if detected, rest, ok := detectLineLevel(message); ok {
+			level, message = detected, rest
+		}
+	}
+
+	target := lw.logger
+	if target == nil {
+		target = Logger()
+	}
+	l, ok := target.(*logger)
+	if !ok {
+		writeThrough(target, &models.LogEvent{Level: level, Message: message, Prefix: lw.prefix})
+		return
+	}
+	if !l.enabled(level) {
+		return
+	}
+
+	logEvent := l.buildEvent(nil, level, message, make(map[string]interface{}))
+	if lw.prefix != "" {
+		logEvent.Prefix = lw.prefix
+	}
+
+	l.writeEvent(logEvent)
+}
+
+// lineLevels are the level names recognised at the start of a line
+var lineLevels = map[string]log.Level{
+	"trace":   log.TraceLevel,
+	"debug":   log.DebugLevel,
+	"info":    log.InfoLevel,
+	"warn":    log.WarnLevel,
+	"warning": log.WarnLevel,
+	"error":   log.ErrorLevel,
+	"fatal":   log.FatalLevel,
+	"panic":   log.PanicLevel,
+}
+
+// detectLineLevel recognises a leading level marker: a level name in brackets, in upper case or
+// followed by a colon, or level=name. It returns the level and the message without the marker.
+func detectLineLevel(line string) (log.Level, string, bool) {
+	trimmed := strings.TrimLeft(line, " \t")
+
+	var word, rest string
+	switch {
+	case strings.HasPrefix(trimmed, "["):
+		end := strings.IndexByte(trimmed, ']')
+		if end < 0 {
+			return 0, line, false
+		}
+		word, rest = trimmed[1:end], trimmed[end+1:]
+	case len(trimmed) > len("level=") && strings.EqualFold(trimmed[:len("level=")], "level="):
+		word, rest, _ = strings.Cut(trimmed[len("level="):], " ")
+		word = strings.Trim(word, `"`)
+	default:
+		end := strings.IndexAny(trimmed, " :")
+		if end < 0 {
+			end = len(trimmed)
+		}
+		word, rest = trimmed[:end], trimmed[end:]
+
+		// A bare word must be upper case or followed by a colon, so "Error connecting" is left alone
+		colon := strings.HasPrefix(rest, ":")
+		if !colon && word != strings.ToUpper(word) {
+			return 0, line, false
+		}
+		rest = strings.TrimPrefix(rest, ":")
+	}
+
+	level, ok := lineLevels[strings.ToLower(strings.TrimSpace(word))]
+	if !ok {
+		return 0, line, false
+	}
+	return level, strings.TrimLeft(rest, " \t:"), true
+}
@@ -0,0 +1,158 @@
+package arbor
+
+import (
+	"errors"
+	"fmt"
+	stdlog "log"
+	"strings"
+	"testing"
+
+	"github.com/phuslu/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLineWriter_FramesLines(t *testing.T) {
+	emitter, capture := newCaptureLogger()
+	w := NewLineWriter(emitter.WithCorrelationId("job-1").WithStr("component", "kafka"),
+		&LineWriterOptions{Level: WarnLevel, Prefix: "kafka"})
+
+	fmt.Fprint(w, "first line\nsecond ")
+	fmt.Fprint(w, "line\r\n\n   \nthird")
+	require.Len(t, capture.Events(), 2, "a partial line waits for its newline")
+
+	require.NoError(t, w.Close())
+
+	events := capture.Events()
+	require.Len(t, events, 3)
+	assert.Equal(t, []string{"first line", "second line", "third"},
+		[]string{events[0].Message, events[1].Message, events[2].Message})
+	for _, event := range events {
+		assert.Equal(t, log.WarnLevel, event.Level)
+		assert.Equal(t, "kafka", event.Prefix)
+		assert.Equal(t, "job-1", event.CorrelationID)
+		assert.Equal(t, "kafka", event.Fields["component"])
+	}
+}
+
+func TestLineWriter_LongLines(t *testing.T) {
+	emitter, capture := newCaptureLogger()
+	w := NewLineWriter(emitter, nil)
+
+	fmt.Fprint(w, strings.Repeat("x", maxLineLength+10))
+	w.Close()
+
+	events := capture.Events()
+	require.Len(t, events, 2, "lines longer than maxLineLength are split")
+	assert.Len(t, events[0].Message, maxLineLength)
+	assert.Len(t, events[1].Message, 10)
+	assert.Equal(t, log.InfoLevel, events[0].Level, "the default level is info")
+}
+
+func TestLineWriter_DetectLevel(t *testing.T) {
+	emitter, capture := newCaptureLogger()
+	w := NewLineWriter(emitter.WithPrefix("lib"), &LineWriterOptions{DetectLevel: true})
+
+	lines := []struct {
+		line    string
+		level   log.Level
+		message string
+	}{
+		{"[ERROR] connection refused", log.ErrorLevel, "connection refused"},
+		{"WARN: disk nearly full", log.WarnLevel, "disk nearly full"},
+		{"debug: cache miss", log.DebugLevel, "cache miss"},
+		{"level=trace msg=polling", log.TraceLevel, "msg=polling"},
+		{"[warning]retrying", log.WarnLevel, "retrying"},
+		{"FATAL giving up", log.FatalLevel, "giving up"},
+		{"Error connecting is not a marker", log.InfoLevel, "Error connecting is not a marker"},
+		{"[db] plain message", log.InfoLevel, "[db] plain message"},
+		{"no marker", log.InfoLevel, "no marker"},
+	}
+	for _, tt := range lines {
+		fmt.Fprintln(w, tt.line)
+	}
+
+	events := capture.Events()
+	require.Len(t, events, len(lines), "fatal lines are logged without exiting")
+	for i, tt := range lines {
+		assert.Equal(t, tt.level, events[i].Level, tt.line)
+		assert.Equal(t, tt.message, events[i].Message, tt.line)
+		assert.Equal(t, "lib", events[i].Prefix, tt.line)
+	}
+}
+
+func TestLineWriter_RespectsLoggerLevel(t *testing.T) {
+	emitter, capture := newCaptureLogger()
+	w := NewLineWriter(emitter.WithLevel(WarnLevel), &LineWriterOptions{DetectLevel: true})
+
+	fmt.Fprintln(w, "DEBUG dropped")
+	fmt.Fprintln(w, "ERROR kept")
+
+	events := capture.Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, "kept", events[0].Message)
+}
+
+func TestLineWriter_OtherILogger(t *testing.T) {
+	emitter, capture := newCaptureLogger()
+	restore := SetDefault(wrappedLogger{emitter.WithCorrelationId("job-2")})
+	defer restore()
+
+	w := NewLineWriter(wrappedLogger{emitter.WithPrefix("kafka")}, &LineWriterOptions{DetectLevel: true})
+	fmt.Fprintln(w, "WARN: rebalancing")
+	fmt.Fprintln(w, "FATAL giving up")
+	fmt.Fprintln(w, fmt.Errorf("ERROR commit: %w", errors.New("broker unavailable")))
+	StdLogger(InfoLevel).Print("via the default logger")
+
+	events := capture.Events()
+	require.Len(t, events, 4, "lines are written through the given logger, not a replacement")
+	assert.Equal(t, log.WarnLevel, events[0].Level)
+	assert.Equal(t, "rebalancing", events[0].Message)
+	assert.Equal(t, "kafka", events[0].Prefix)
+	assert.Equal(t, log.ErrorLevel, events[1].Level, "fatal lines are written at error level")
+	assert.Equal(t, log.ErrorLevel, events[2].Level)
+	assert.Equal(t, "commit: broker unavailable", events[2].Message, "a wrapped error is written as its full text")
+	assert.Equal(t, "via the default logger", events[3].Message)
+	assert.Equal(t, "job-2", events[3].CorrelationID)
+}
+
+func TestStdLogger(t *testing.T) {
+	emitter, capture := newCaptureLogger()
+	restore := SetDefault(emitter.WithPrefix("http"))
+	defer restore()
+
+	std := StdLogger(ErrorLevel)
+	std.Printf("http: TLS handshake error from %s", "10.0.0.1")
+
+	events := capture.Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, log.ErrorLevel, events[0].Level)
+	assert.Equal(t, "http: TLS handshake error from 10.0.0.1", events[0].Message)
+	assert.Equal(t, "http", events[0].Prefix)
+}
+
+func TestRedirectStdLog(t *testing.T) {
+	emitter, capture := newCaptureLogger()
+	restoreDefault := SetDefault(emitter)
+	defer restoreDefault()
+
+	stdlog.SetPrefix("app: ")
+	defer stdlog.SetPrefix("")
+	output, flags := stdlog.Writer(), stdlog.Flags()
+
+	restore := RedirectStdLog()
+	stdlog.Printf("[WARN] cache miss for %s", "user:42")
+	stdlog.Println("started")
+	restore()
+
+	events := capture.Events()
+	require.Len(t, events, 2)
+	assert.Equal(t, log.WarnLevel, events[0].Level)
+	assert.Equal(t, "cache miss for user:42", events[0].Message)
+	assert.Equal(t, log.InfoLevel, events[1].Level)
+	assert.Equal(t, "started", events[1].Message, "the standard logger's prefix is cleared")
+
+	assert.Equal(t, output, stdlog.Writer(), "restore reinstates the previous output")
+	assert.Equal(t, "app: ", stdlog.Prefix())
+	assert.Equal(t, flags, stdlog.Flags())
+}
@@ -21,13 +21,21 @@ type ginTransformer struct {
 	correlationID  string
 	correlationMux sync.RWMutex
 	getWriters     func() map[string]writers.IWriter // Function to get registered writers
+	process        func(*models.LogEvent) bool       // Optional processing before writers; false drops the event
 }
 
 // NewGinTransformer creates a new Gin transformer that integrates with arbor's logging infrastructure
 func NewGinTransformer(config models.WriterConfiguration, getWriters func() map[string]writers.IWriter) io.Writer {
+	return NewGinTransformerWithProcessor(config, getWriters, nil)
+}
+
+// NewGinTransformerWithProcessor creates a Gin transformer that passes each event to process
+// before the writers, e.g. to apply hooks and redaction. Events for which process returns false are dropped.
+func NewGinTransformerWithProcessor(config models.WriterConfiguration, getWriters func() map[string]writers.IWriter, process func(*models.LogEvent) bool) io.Writer {
 	return &ginTransformer{
 		config:     config,
 		getWriters: getWriters,
+		process:    process,
 	}
 }
 
@@ -56,6 +64,10 @@ func (gt *ginTransformer) Write(p []byte) (n int, err error) {
 		return len(p), nil
 	}
 
+	if gt.process != nil && !gt.process(logEvent) {
+		return len(p), nil
+	}
+
 	// Transform to arbor format and send to all registered writers
 	gt.outputToRegisteredWriters(logEvent)
 
@@ -166,19 +178,27 @@ func (gt *ginTransformer) outputToRegisteredWriters(logEvent *models.LogEvent) {
 		return
 	}
 
-	// Marshal the log event to JSON for writers
-	jsonData, err := json.Marshal(logEvent)
-	if err != nil {
-		internalLog.Error().Err(err).Msg("Failed to marshal log event")
-		return
-	}
-
-	// Send to all registered writers
+	// Send to all registered writers, marshalling to JSON only for legacy writers
+	var jsonData []byte
 	for writerName, writer := range registeredWriters {
-		if writer != nil {
+		if writer == nil {
+			continue
+		}
+
+		if eventWriter, ok := writer.(writers.IEventWriter); ok {
+			eventWriter.WriteEvent(logEvent)
+		} else {
+			if jsonData == nil {
+				data, err := json.Marshal(logEvent)
+				if err != nil {
+					internalLog.Error().Err(err).Msg("Failed to marshal log event")
+					return
+				}
+				jsonData = data
+			}
 			writer.Write(jsonData)
-			internalLog.Trace().Msgf("Sent Gin log to writer: %s", writerName)
 		}
+		internalLog.Trace().Msgf("Sent Gin log to writer: %s", writerName)
 	}
 }
 
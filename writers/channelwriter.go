@@ -17,6 +17,7 @@ type channelWriter struct {
 	buffer     chan models.LogEvent
 	bufferSize int
 	done       chan struct{}
+	flushes    chan chan struct{}
 	processor  func(models.LogEvent) error
 	running    bool
 	runningMux sync.RWMutex
@@ -38,6 +39,7 @@ func NewChannelWriter(config models.WriterConfiguration, bufferSize int, process
 		buffer:     make(chan models.LogEvent, bufferSize),
 		bufferSize: bufferSize,
 		done:       make(chan struct{}),
+		flushes:    make(chan chan struct{}),
 		processor:  processor,
 		running:    false,
 	}, nil
@@ -103,6 +105,19 @@ func (cw *channelWriter) processBuffer() {
 			if err := cw.processor(entry); err != nil {
 				internalLog.Warn().Err(err).Msg("Failed to process log entry")
 			}
+		case flushed := <-cw.flushes:
+			// Drain everything queued before the flush request
+			for drained := false; !drained; {
+				select {
+				case entry := <-cw.buffer:
+					if err := cw.processor(entry); err != nil {
+						internalLog.Warn().Err(err).Msg("Failed to process log entry during flush")
+					}
+				default:
+					drained = true
+				}
+			}
+			close(flushed)
 		case <-cw.done:
 			for {
 				select {
@@ -123,8 +138,6 @@ func (cw *channelWriter) Write(data []byte) (int, error) {
 		return len(data), nil
 	}
 
-	internalLog := common.NewLogger().WithContext("function", "channelWriter.Write").GetLogger()
-
 	n := len(data)
 	if n == 0 {
 		return n, nil
@@ -135,21 +148,58 @@ func (cw *channelWriter) Write(data []byte) (int, error) {
 		return 0, err
 	}
 
+	cw.WriteEvent(&logEvent)
+	return n, nil
+}
+
+// WriteEvent filters the event by level and queues a copy for the processor
+func (cw *channelWriter) WriteEvent(logEvent *models.LogEvent) error {
+	if !cw.IsRunning() {
+		return nil
+	}
+
 	cw.configMux.RLock()
 	minLevel := cw.config.Level.ToLogLevel()
 	cw.configMux.RUnlock()
 
 	if logEvent.Level < minLevel {
-		return n, nil
+		return nil
 	}
 
 	select {
-	case cw.buffer <- logEvent:
-		return n, nil
+	case cw.buffer <- *logEvent:
+		return nil
 	default:
+		internalLog := common.NewLogger().WithContext("function", "channelWriter.WriteEvent").GetLogger()
 		internalLog.Warn().Msg("Channel writer buffer full, dropping entry")
-		return n, nil
+		return nil
+	}
+}
+
+// Flush blocks until every event queued before the call has been passed to the processor.
+// Returns immediately if the writer is not running.
+func (cw *channelWriter) Flush() error {
+	cw.runningMux.RLock()
+	running, done := cw.running, cw.done
+	cw.runningMux.RUnlock()
+
+	if !running {
+		return nil
+	}
+
+	flushed := make(chan struct{})
+	select {
+	case cw.flushes <- flushed:
+	case <-done:
+		// Stop drains the buffer before returning
+		return nil
+	}
+
+	select {
+	case <-flushed:
+	case <-done:
 	}
+	return nil
 }
 
 func (cw *channelWriter) WithLevel(level log.Level) IWriter {
@@ -159,6 +209,13 @@ func (cw *channelWriter) WithLevel(level log.Level) IWriter {
 	return cw
 }
 
+// GetLevel returns the writer's minimum level
+func (cw *channelWriter) GetLevel() log.Level {
+	cw.configMux.RLock()
+	defer cw.configMux.RUnlock()
+	return cw.config.Level.ToLogLevel()
+}
+
 func (cw *channelWriter) GetFilePath() string {
 	return ""
 }
@@ -343,6 +343,45 @@ func TestChannelWriter_Write_InvalidJSON(t *testing.T) {
 	}
 }
 
+func TestChannelWriter_WriteEvent_PreservesFieldTypes(t *testing.T) {
+	config := models.WriterConfiguration{Level: levels.InfoLevel}
+
+	var collected []models.LogEvent
+	var mu sync.Mutex
+	writer, err := NewChannelWriter(config, 1000, createCollectingProcessor(&collected, &mu))
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	if err := writer.Start(); err != nil {
+		t.Fatalf("Failed to start writer: %v", err)
+	}
+
+	event := createTestLogEvent(log.InfoLevel, "typed", "typed fields")
+	event.Fields["count"] = 42
+	if err := writer.WriteEvent(&event); err != nil {
+		t.Fatalf("WriteEvent failed: %v", err)
+	}
+
+	// Below the writer's level - filtered out
+	debugEvent := createTestLogEvent(log.DebugLevel, "typed", "filtered")
+	if err := writer.WriteEvent(&debugEvent); err != nil {
+		t.Fatalf("WriteEvent failed: %v", err)
+	}
+
+	if err := writer.Stop(); err != nil {
+		t.Fatalf("Failed to stop writer: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(collected) != 1 {
+		t.Fatalf("Expected 1 processed entry, got %d", len(collected))
+	}
+	if _, ok := collected[0].Fields["count"].(int); !ok {
+		t.Errorf("Expected int field to stay int, got %T", collected[0].Fields["count"])
+	}
+}
+
 func TestChannelWriter_Write_EmptyData(t *testing.T) {
 	config := models.WriterConfiguration{Level: levels.TraceLevel}
 	processor := func(models.LogEvent) error { return nil }
@@ -1176,3 +1215,42 @@ func TestChannelWriter_ProcessorError_ContinuesProcessing(t *testing.T) {
 		t.Error("Expected channel writer to continue running after processor errors")
 	}
 }
+
+func TestChannelWriter_Flush(t *testing.T) {
+	config := models.WriterConfiguration{Level: levels.TraceLevel}
+	var counter atomic.Int64
+	processor := createDelayedProcessor(&counter, time.Millisecond)
+
+	writer, err := NewChannelWriter(config, 100, processor)
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	defer writer.Close()
+
+	// Flushing a stopped writer returns immediately
+	if err := writer.Flush(); err != nil {
+		t.Fatalf("Flush() before Start failed: %v", err)
+	}
+
+	if err := writer.Start(); err != nil {
+		t.Fatalf("Failed to start writer: %v", err)
+	}
+
+	for i := 0; i < 20; i++ {
+		event := createTestLogEvent(log.InfoLevel, "flush", "message")
+		if err := writer.WriteEvent(&event); err != nil {
+			t.Fatalf("WriteEvent failed: %v", err)
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		t.Fatalf("Flush() failed: %v", err)
+	}
+
+	if counter.Load() != 20 {
+		t.Errorf("Expected all 20 entries processed after Flush(), got %d", counter.Load())
+	}
+	if !writer.IsRunning() {
+		t.Error("Expected writer to keep running after Flush()")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"sync/atomic"
 
 	"github.com/ternarybob/arbor/models"
 
@@ -47,7 +48,7 @@ func ConsoleWriter(config models.WriterConfiguration) IWriter {
 		config: config,
 	}
 
-	return cw
+	return withDedup(cw, config)
 }
 
 func (cw *consoleWriter) WithLevel(level log.Level) IWriter {
@@ -55,6 +56,11 @@ func (cw *consoleWriter) WithLevel(level log.Level) IWriter {
 	return cw
 }
 
+// GetLevel returns the writer's minimum level
+func (cw *consoleWriter) GetLevel() log.Level {
+	return log.Level(atomic.LoadUint32((*uint32)(&cw.logger.Level)))
+}
+
 // GetFilePath returns empty string as console writer doesn't write to files
 func (cw *consoleWriter) GetFilePath() string {
 	return ""
@@ -75,54 +81,17 @@ func (cw *consoleWriter) Write(data []byte) (n int, err error) {
 		return n, nil
 	}
 
-	// Use phuslu logger with the parsed log event data
-	var phusluEvent *log.Entry
-	switch logEvent.Level {
-	case log.TraceLevel:
-		phusluEvent = cw.logger.Trace()
-	case log.DebugLevel:
-		phusluEvent = cw.logger.Debug()
-	case log.InfoLevel:
-		phusluEvent = cw.logger.Info()
-	case log.WarnLevel:
-		phusluEvent = cw.logger.Warn()
-	case log.ErrorLevel:
-		phusluEvent = cw.logger.Error()
-	case log.FatalLevel:
-		phusluEvent = cw.logger.Fatal()
-	case log.PanicLevel:
-		phusluEvent = cw.logger.Panic()
-	default:
-		phusluEvent = cw.logger.Info()
-	}
-
-	// Add arbor-specific fields to phuslu logger
-	if logEvent.Prefix != "" {
-		phusluEvent = phusluEvent.Str("prefix", logEvent.Prefix)
-	}
-	if logEvent.Function != "" {
-		phusluEvent = phusluEvent.Str("function", logEvent.Function)
-	}
-	if logEvent.CorrelationID != "" {
-		phusluEvent = phusluEvent.Str("correlationid", logEvent.CorrelationID)
-	}
-
-	// Add custom fields from arbor
-	for key, value := range logEvent.Fields {
-		phusluEvent = phusluEvent.Interface(key, value)
-	}
-
-	// Add error if present
-	if logEvent.Error != "" {
-		phusluEvent = phusluEvent.Str("error", logEvent.Error)
-	}
-
-	// Send the message through phuslu (uses phuslu's default console format)
-	phusluEvent.Msg(logEvent.Message)
+	cw.WriteEvent(&logEvent)
 
 	return n, nil
 }
 
+// WriteEvent renders the log event directly, without a JSON round trip
+func (cw *consoleWriter) WriteEvent(logEvent *models.LogEvent) error {
+	writePhusluEvent(&cw.logger, logEvent)
+	return nil
+}
+
 func (cw *consoleWriter) Close() error {
 	return nil
 }
@@ -206,20 +175,34 @@ func consoleFormatter(w io.Writer, a *log.FormatterArgs) (int, error) {
 		p += a.Message
 	}
 
+	writeField := func(key, value string) {
+		if messageColor != "" {
+			p += fmt.Sprintf(" %s%s%s=%s%v%s",
+				messageColor, key, colorReset,
+				messageColor, value, colorReset,
+			)
+		} else {
+			p += fmt.Sprintf(" %s%s%s=%s%v%s", colorFieldKeyBlue, key, colorReset, colorFieldGray, value, colorReset)
+		}
+	}
+
 	// KeyValues - use level color for warn/error/fatal/panic, no background
+	// Nested objects are expanded to dotted keys
 	if len(a.KeyValues) > 0 {
 		for _, kv := range a.KeyValues {
-			if messageColor != "" {
-				p += fmt.Sprintf(" %s%s%s=%s%v%s",
-					messageColor, kv.Key, colorReset,
-					messageColor, kv.Value, colorReset,
-				)
-			} else {
-				p += fmt.Sprintf(" %s%s%s=%s%v%s", colorFieldKeyBlue, kv.Key, colorReset, colorFieldGray, kv.Value, colorReset)
-			}
+			flattenFormatterField(kv.Key, kv.Value, kv.ValueType, writeField)
 		}
 	}
 
+	if a.Caller != "" {
+		writeField("caller", formatterCaller(a.Caller))
+	}
+
+	// phuslu parses a "stack" key into its own argument rather than KeyValues
+	if a.Stack != "" {
+		flattenFormatterField("stack", a.Stack, 'o', writeField)
+	}
+
 	p += "\n"
 
 	return w.Write([]byte(p))
@@ -88,3 +88,40 @@ func TestConsoleWriter_Write(t *testing.T) {
 		})
 	}
 }
+
+func TestWriters_GetLevel(t *testing.T) {
+	config := models.WriterConfiguration{
+		Type:  models.LogWriterTypeConsole,
+		Level: levels.InfoLevel,
+		Dedup: &models.DedupConfiguration{},
+	}
+
+	memWriter := MemoryWriter(config)
+	defer memWriter.Close()
+
+	tests := map[string]IWriter{
+		"console":  ConsoleWriter(config),
+		"file":     FileWriter(models.WriterConfiguration{Level: levels.InfoLevel, FileName: t.TempDir() + "/level.log"}),
+		"memory":   memWriter,
+		"logstore": LogStoreWriter(memWriter.GetStore(), config),
+	}
+
+	for name, writer := range tests {
+		reporter, ok := writer.(ILevelReporter)
+		if !ok {
+			t.Errorf("%s writer should implement ILevelReporter", name)
+			continue
+		}
+		if level := reporter.GetLevel(); level != log.InfoLevel {
+			t.Errorf("%s writer: expected level %v, got %v", name, log.InfoLevel, level)
+		}
+
+		writer.WithLevel(log.DebugLevel)
+		if level := reporter.GetLevel(); level != log.DebugLevel {
+			t.Errorf("%s writer: expected level %v after WithLevel, got %v", name, log.DebugLevel, level)
+		}
+		if name != "memory" {
+			writer.Close()
+		}
+	}
+}
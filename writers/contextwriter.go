@@ -40,6 +40,13 @@ func (cw *ContextWriter) Write(p []byte) (n int, err error) {
 		return 0, err
 	}
 
+	cw.WriteEvent(&logEvent)
+
+	return len(p), nil
+}
+
+// WriteEvent filters the event by log level and sends it to the singleton context buffer.
+func (cw *ContextWriter) WriteEvent(logEvent *models.LogEvent) error {
 	// Check log level filter
 	cw.configMux.RLock()
 	minLevel := cw.config.Level.ToLogLevel()
@@ -47,13 +54,13 @@ func (cw *ContextWriter) Write(p []byte) (n int, err error) {
 
 	// Filter out logs below minimum level
 	if logEvent.Level < minLevel {
-		return len(p), nil
+		return nil
 	}
 
 	// Send directly to singleton context buffer
-	common.Log(logEvent)
+	common.Log(*logEvent)
 
-	return len(p), nil
+	return nil
 }
 
 // WithLevel sets the minimum log level for this writer.
@@ -64,11 +71,23 @@ func (cw *ContextWriter) WithLevel(level log.Level) IWriter {
 	return cw
 }
 
+// GetLevel returns the writer's minimum level
+func (cw *ContextWriter) GetLevel() log.Level {
+	cw.configMux.RLock()
+	defer cw.configMux.RUnlock()
+	return cw.config.Level.ToLogLevel()
+}
+
 // GetFilePath returns an empty string (context writers don't write to files).
 func (cw *ContextWriter) GetFilePath() string {
 	return ""
 }
 
+// Flush sends the events held by the singleton context buffer to its channel
+func (cw *ContextWriter) Flush() error {
+	return common.Flush()
+}
+
 // Close returns nil immediately since there are no resources to clean up.
 // The singleton context buffer lifecycle is managed separately via common.Stop().
 func (cw *ContextWriter) Close() error {
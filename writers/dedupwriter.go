@@ -0,0 +1,233 @@
+package writers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/phuslu/log"
+	"github.com/ternarybob/arbor/models"
+)
+
+// dedupEntry tracks one distinct event within its window
+type dedupEntry struct {
+	count      int             // events seen in the window, including those written
+	suppressed int             // events collapsed into the repeated event
+	last       models.LogEvent // most recent suppressed event, reported when the window closes
+	timer      *time.Timer     // closes the window
+}
+
+// dedupWriter wraps a writer, collapsing repeated events within a window into a
+// single event carrying a repeated=N field
+type dedupWriter struct {
+	writer IWriter
+	window time.Duration
+	burst  int
+	fields []string
+	mu     sync.Mutex
+	active map[string]*dedupEntry
+	closed bool
+}
+
+// DedupWriter wraps writer so that events repeated within config.Window are collapsed.
+// The first config.Burst occurrences in a window are written; further repeats are counted
+// and, when the window closes, the last one is written with a "repeated" field holding the count.
+func DedupWriter(writer IWriter, config models.DedupConfiguration) IWriter {
+	burst := config.Burst
+	if burst < 1 {
+		burst = 1
+	}
+	window := config.Window
+	if window <= 0 {
+		window = time.Second
+	}
+
+	return &dedupWriter{
+		writer: writer,
+		window: window,
+		burst:  burst,
+		fields: append([]string(nil), config.Fields...),
+		active: make(map[string]*dedupEntry),
+	}
+}
+
+// withDedup wraps a writer built from config in a DedupWriter when config.Dedup is set
+func withDedup(writer IWriter, config models.WriterConfiguration) IWriter {
+	if config.Dedup == nil {
+		return writer
+	}
+	return DedupWriter(writer, *config.Dedup)
+}
+
+// Write deduplicates a JSON encoded event; data that is not a log event is passed through
+func (dw *dedupWriter) Write(data []byte) (int, error) {
+	var logEvent models.LogEvent
+	if err := json.Unmarshal(data, &logEvent); err != nil {
+		return dw.writer.Write(data)
+	}
+
+	if !dw.allow(&logEvent) {
+		return len(data), nil
+	}
+	return dw.writer.Write(data)
+}
+
+// WriteEvent forwards the event unless it repeats one already written in the current window
+func (dw *dedupWriter) WriteEvent(logEvent *models.LogEvent) error {
+	if !dw.allow(logEvent) {
+		return nil
+	}
+	return dw.forward(logEvent)
+}
+
+// allow records the event and reports whether it should be written now
+func (dw *dedupWriter) allow(logEvent *models.LogEvent) bool {
+	key := dw.key(logEvent)
+
+	dw.mu.Lock()
+	defer dw.mu.Unlock()
+
+	if dw.closed {
+		return true
+	}
+
+	entry, exists := dw.active[key]
+	if !exists {
+		entry = &dedupEntry{}
+		entry.timer = time.AfterFunc(dw.window, func() { dw.closeWindow(key, entry) })
+		dw.active[key] = entry
+	}
+
+	entry.count++
+	if entry.count <= dw.burst {
+		return true
+	}
+
+	// Events are shared between writers, so keep a copy rather than the pointer
+	entry.suppressed++
+	entry.last = *logEvent
+	return false
+}
+
+// closeWindow writes the repeated event for the window opened by entry, if any events were suppressed.
+// The window may already have been closed by Flush or Close.
+func (dw *dedupWriter) closeWindow(key string, entry *dedupEntry) {
+	dw.mu.Lock()
+	current, exists := dw.active[key]
+	if exists && current == entry {
+		delete(dw.active, key)
+	}
+	dw.mu.Unlock()
+
+	if exists && current == entry {
+		dw.reportRepeated(entry)
+	}
+}
+
+// reportRepeated writes the last suppressed event with the number of suppressed repeats
+func (dw *dedupWriter) reportRepeated(entry *dedupEntry) {
+	if entry.suppressed == 0 {
+		return
+	}
+
+	repeated := entry.last
+	repeated.Fields = make(map[string]interface{}, len(entry.last.Fields)+1)
+	for key, value := range entry.last.Fields {
+		repeated.Fields[key] = value
+	}
+	repeated.Fields["repeated"] = entry.suppressed
+
+	dw.forward(&repeated)
+}
+
+// closeAll closes every open window, writing the repeated events
+func (dw *dedupWriter) closeAll() {
+	dw.mu.Lock()
+	pending := dw.active
+	dw.active = make(map[string]*dedupEntry)
+	dw.mu.Unlock()
+
+	for _, key := range sortedDedupKeys(pending) {
+		entry := pending[key]
+		entry.timer.Stop()
+		dw.reportRepeated(entry)
+	}
+}
+
+// key identifies repeats by level, prefix, message and the configured field values
+func (dw *dedupWriter) key(logEvent *models.LogEvent) string {
+	var b strings.Builder
+	b.WriteString(logEvent.Level.String())
+	b.WriteByte('|')
+	b.WriteString(logEvent.Prefix)
+	b.WriteByte('|')
+	b.WriteString(logEvent.Message)
+	for _, field := range dw.fields {
+		b.WriteByte('|')
+		if value, exists := logEvent.Fields[field]; exists {
+			b.WriteString(fmt.Sprint(value))
+		}
+	}
+	return b.String()
+}
+
+// forward writes an event to the wrapped writer, encoding it for legacy writers
+func (dw *dedupWriter) forward(logEvent *models.LogEvent) error {
+	if eventWriter, ok := dw.writer.(IEventWriter); ok {
+		return eventWriter.WriteEvent(logEvent)
+	}
+	data, err := json.Marshal(logEvent)
+	if err != nil {
+		return err
+	}
+	_, err = dw.writer.Write(data)
+	return err
+}
+
+// Flush closes every open window so pending repeat counts are written, then flushes the wrapped writer
+func (dw *dedupWriter) Flush() error {
+	dw.closeAll()
+	if flusher, ok := dw.writer.(IFlusher); ok {
+		return flusher.Flush()
+	}
+	return nil
+}
+
+func (dw *dedupWriter) WithLevel(level log.Level) IWriter {
+	dw.writer.WithLevel(level)
+	return dw
+}
+
+// GetLevel returns the wrapped writer's level, or 0 if it does not report one
+func (dw *dedupWriter) GetLevel() log.Level {
+	return WriterLevel(dw.writer)
+}
+
+func (dw *dedupWriter) GetFilePath() string {
+	return dw.writer.GetFilePath()
+}
+
+// Close writes pending repeat counts, then closes the wrapped writer. It marks the writer
+// closed first, so events arriving meanwhile pass through instead of opening new windows.
+func (dw *dedupWriter) Close() error {
+	dw.mu.Lock()
+	dw.closed = true
+	dw.mu.Unlock()
+
+	dw.closeAll()
+
+	return dw.writer.Close()
+}
+
+// sortedDedupKeys returns the keys of open windows in a stable order
+func sortedDedupKeys(entries map[string]*dedupEntry) []string {
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,119 @@
+package writers
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/phuslu/log"
+	"github.com/ternarybob/arbor/levels"
+	"github.com/ternarybob/arbor/models"
+)
+
+func writeRepeated(writer IWriter, n int, message string, fields map[string]interface{}) {
+	for i := 0; i < n; i++ {
+		event := createTestLogEvent(log.ErrorLevel, "", message)
+		for key, value := range fields {
+			event.Fields[key] = value
+		}
+		writer.(IEventWriter).WriteEvent(&event)
+	}
+}
+
+func TestDedupWriter_CollapsesRepeatsWithinWindow(t *testing.T) {
+	recorder := &recordingWriter{}
+	writer := DedupWriter(recorder, models.DedupConfiguration{Window: 30 * time.Millisecond})
+
+	writeRepeated(writer, 100, "db unavailable", nil)
+	writeRepeated(writer, 1, "cache unavailable", nil)
+
+	events := recorder.Events()
+	if len(events) != 2 {
+		t.Fatalf("Expected the first occurrence of each message, got %d events", len(events))
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	events = recorder.Events()
+	if len(events) != 3 {
+		t.Fatalf("Expected one repeated event after the window closed, got %d events", len(events))
+	}
+	repeated := events[2]
+	if repeated.Message != "db unavailable" || repeated.Fields["repeated"] != 99 {
+		t.Errorf("Expected db unavailable with repeated=99, got %q %v", repeated.Message, repeated.Fields)
+	}
+
+	// A new window starts after the previous one closed
+	writeRepeated(writer, 1, "db unavailable", nil)
+	if len(recorder.Events()) != 4 {
+		t.Errorf("Expected the message to be written again in a new window")
+	}
+	writer.Close()
+}
+
+func TestDedupWriter_BurstAndFields(t *testing.T) {
+	recorder := &recordingWriter{}
+	writer := DedupWriter(recorder, models.DedupConfiguration{
+		Window: time.Hour,
+		Burst:  2,
+		Fields: []string{"host"},
+	})
+
+	writeRepeated(writer, 5, "timeout", map[string]interface{}{"host": "a", "attempt": 1})
+	writeRepeated(writer, 5, "timeout", map[string]interface{}{"host": "b"})
+
+	if got := len(recorder.Events()); got != 4 {
+		t.Fatalf("Expected a burst of 2 per host, got %d events", got)
+	}
+
+	// Close writes the pending repeat counts
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close should not return error: %v", err)
+	}
+
+	events := recorder.Events()
+	if len(events) != 6 {
+		t.Fatalf("Expected repeated events for both hosts on close, got %d events", len(events))
+	}
+	for _, event := range events[4:] {
+		if event.Fields["repeated"] != 3 {
+			t.Errorf("Expected repeated=3, got %v", event.Fields)
+		}
+	}
+	if events[4].Fields["host"] != "a" || events[4].Fields["attempt"] != 1 {
+		t.Errorf("Expected the repeated event to keep the original fields, got %v", events[4].Fields)
+	}
+	if !recorder.closed {
+		t.Error("Expected the wrapped writer to be closed")
+	}
+
+	// Once closed, repeats pass through without opening windows
+	writeRepeated(writer, 3, "timeout", map[string]interface{}{"host": "a"})
+	if got := len(recorder.Events()); got != 9 {
+		t.Errorf("Expected events after Close to pass through, got %d events", got)
+	}
+	if open := len(writer.(*dedupWriter).active); open != 0 {
+		t.Errorf("Expected no windows opened after Close, got %d", open)
+	}
+}
+
+func TestDedupWriter_FromWriterConfiguration(t *testing.T) {
+	config := models.WriterConfiguration{
+		Type:       models.LogWriterTypeFile,
+		Level:      levels.TraceLevel,
+		FileName:   filepath.Join(t.TempDir(), "dedup.log"),
+		OutputType: models.OutputFormatLogfmt,
+		Dedup:      &models.DedupConfiguration{Window: time.Hour},
+	}
+
+	writer := FileWriter(config)
+	if _, ok := writer.(*dedupWriter); !ok {
+		t.Fatalf("Expected FileWriter to be wrapped when Dedup is configured, got %T", writer)
+	}
+	writer.Close()
+
+	config.Dedup = nil
+	if _, ok := FileWriter(config).(*fileWriter); !ok {
+		t.Error("Expected FileWriter to be unwrapped without Dedup configured")
+	}
+}
@@ -0,0 +1,152 @@
+package writers
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/phuslu/log"
+)
+
+// appendPhusluField adds an arbor field to a phuslu entry using the matching typed encoder,
+// so nested objects, times and slices are written as native JSON rather than strings.
+func appendPhusluField(entry *log.Entry, key string, value interface{}) *log.Entry {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return entry.Dict(key, phusluDict(v))
+	case time.Time:
+		return entry.Time(key, v)
+	case uint64:
+		return entry.Uint64(key, v)
+	case []int:
+		return entry.Ints(key, v)
+	default:
+		return entry.Any(key, value)
+	}
+}
+
+// phusluDict encodes a nested arbor object as a phuslu context
+func phusluDict(fields map[string]interface{}) log.Context {
+	dict := log.NewContext(nil)
+	for _, key := range sortedKeys(fields) {
+		dict = appendPhusluField(dict, key, fields[key])
+	}
+	return dict.Value()
+}
+
+// flattenField renders a field for text output (console, logfmt, memory store).
+// Nested objects are expanded into dotted keys (request.status=200), arrays of
+// objects into indexed keys (errorchain.0.message=...) and other slices are
+// rendered as [a,b,c]; emit is called once per resulting key/value pair.
+func flattenField(key string, value interface{}, emit func(key, value string)) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			emit(key, "{}")
+			return
+		}
+		for _, nestedKey := range sortedKeys(v) {
+			flattenField(key+"."+nestedKey, v[nestedKey], emit)
+		}
+		return
+	case []interface{}:
+		if containsObject(v) {
+			for i, item := range v {
+				flattenField(key+"."+strconv.Itoa(i), item, emit)
+			}
+			return
+		}
+	}
+
+	emit(key, textValue(value))
+}
+
+// flattenFormatterField decodes a value parsed by the phuslu formatter and flattens it.
+// Only objects and arrays (value type 'o') need decoding; scalars are emitted as-is.
+func flattenFormatterField(key, raw string, valueType byte, emit func(key, value string)) {
+	if valueType != 'o' {
+		emit(key, raw)
+		return
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(raw))
+	decoder.UseNumber()
+
+	var decoded interface{}
+	if err := decoder.Decode(&decoded); err != nil {
+		emit(key, raw)
+		return
+	}
+
+	flattenField(key, decoded, emit)
+}
+
+// formatterCaller renders the caller object parsed by the phuslu formatter as "file.go:42".
+// phuslu parses a "caller" key into its own argument rather than KeyValues.
+func formatterCaller(raw string) string {
+	var caller struct {
+		File string `json:"file"`
+		Line int    `json:"line"`
+	}
+	if err := json.Unmarshal([]byte(raw), &caller); err != nil || caller.File == "" {
+		return raw
+	}
+	return filepath.Base(caller.File) + ":" + strconv.Itoa(caller.Line)
+}
+
+// containsObject reports whether any item of a decoded JSON array is an object
+func containsObject(items []interface{}) bool {
+	for _, item := range items {
+		if _, ok := item.(map[string]interface{}); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// textValue renders a single field value as text
+func textValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		parts := make([]string, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			parts[i] = textValue(rv.Index(i).Interface())
+		}
+		return "[" + strings.Join(parts, ",") + "]"
+	case reflect.Map, reflect.Struct, reflect.Ptr:
+		if data, err := json.Marshal(value); err == nil {
+			return string(data)
+		}
+	}
+
+	return fmt.Sprint(value)
+}
+
+// sortedKeys returns the keys of a field map in a stable order
+func sortedKeys(fields map[string]interface{}) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"sync/atomic"
 
 	"github.com/ternarybob/arbor/common"
 	"github.com/ternarybob/arbor/models"
@@ -55,7 +56,7 @@ func FileWriter(config models.WriterConfiguration) IWriter {
 	// Use phuslu file writer with standard backup naming convention
 	fw.initPhusluWriter(fileName, maxSize, maxBackups)
 
-	return fw
+	return withDedup(fw, config)
 }
 
 func (fw *fileWriter) initPhusluWriter(fileName string, maxSize int64, maxBackups int) {
@@ -112,6 +113,11 @@ func (fw *fileWriter) WithLevel(level log.Level) IWriter {
 	return fw
 }
 
+// GetLevel returns the writer's minimum level
+func (fw *fileWriter) GetLevel() log.Level {
+	return log.Level(atomic.LoadUint32((*uint32)(&fw.logger.Level)))
+}
+
 // GetFilePath returns the configured file path (phuslu creates timestamped files automatically)
 func (fw *fileWriter) GetFilePath() string {
 	// Return the base configured filename
@@ -135,54 +141,17 @@ func (fw *fileWriter) Write(data []byte) (n int, err error) {
 		return n, nil
 	}
 
-	// Use phuslu logger with the parsed log event data
-	var phusluEvent *log.Entry
-	switch logEvent.Level {
-	case log.TraceLevel:
-		phusluEvent = fw.logger.Trace()
-	case log.DebugLevel:
-		phusluEvent = fw.logger.Debug()
-	case log.InfoLevel:
-		phusluEvent = fw.logger.Info()
-	case log.WarnLevel:
-		phusluEvent = fw.logger.Warn()
-	case log.ErrorLevel:
-		phusluEvent = fw.logger.Error()
-	case log.FatalLevel:
-		phusluEvent = fw.logger.Fatal()
-	case log.PanicLevel:
-		phusluEvent = fw.logger.Panic()
-	default:
-		phusluEvent = fw.logger.Info()
-	}
-
-	// Add arbor-specific fields to phuslu logger
-	if logEvent.Prefix != "" {
-		phusluEvent = phusluEvent.Str("prefix", logEvent.Prefix)
-	}
-	if logEvent.Function != "" {
-		phusluEvent = phusluEvent.Str("function", logEvent.Function)
-	}
-	if logEvent.CorrelationID != "" {
-		phusluEvent = phusluEvent.Str("correlationid", logEvent.CorrelationID)
-	}
-
-	// Add custom fields from arbor
-	for key, value := range logEvent.Fields {
-		phusluEvent = phusluEvent.Interface(key, value)
-	}
-
-	// Add error if present
-	if logEvent.Error != "" {
-		phusluEvent = phusluEvent.Str("error", logEvent.Error)
-	}
-
-	// Send the message through phuslu (uses phuslu's default console format)
-	phusluEvent.Msg(logEvent.Message)
+	fw.WriteEvent(&logEvent)
 
 	return n, nil
 }
 
+// WriteEvent renders the log event directly, without a JSON round trip
+func (fw *fileWriter) WriteEvent(logEvent *models.LogEvent) error {
+	writePhusluEvent(&fw.logger, logEvent)
+	return nil
+}
+
 func (fw *fileWriter) Close() error {
 	if fileWriter, ok := fw.logger.Writer.(*log.FileWriter); ok {
 		return fileWriter.Close()
@@ -225,29 +194,41 @@ func fileFormatter(w io.Writer, a *log.FormatterArgs) (int, error) {
 		b.WriteString(fmt.Sprintf("%q", a.Message))
 	}
 
-	// Additional key=value fields (logfmt-style)
+	writeField := func(key, value string) {
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(key)
+		b.WriteByte('=')
+
+		// Quote value if it contains spaces or quotes
+		if strings.ContainsAny(value, " \"") {
+			b.WriteString(fmt.Sprintf("%q", value))
+		} else {
+			b.WriteString(value)
+		}
+	}
+
+	// Additional key=value fields (logfmt-style), nested objects expanded to dotted keys
 	if len(a.KeyValues) > 0 {
 		for _, kv := range a.KeyValues {
 			if kv.Key == "" {
 				continue
 			}
 
-			value := fmt.Sprint(kv.Value)
-			if b.Len() > 0 {
-				b.WriteByte(' ')
-			}
-			b.WriteString(kv.Key)
-			b.WriteByte('=')
-
-			// Quote value if it contains spaces or quotes
-			if strings.ContainsAny(value, " \"") {
-				b.WriteString(fmt.Sprintf("%q", value))
-			} else {
-				b.WriteString(value)
-			}
+			flattenFormatterField(kv.Key, kv.Value, kv.ValueType, writeField)
 		}
 	}
 
+	if a.Caller != "" {
+		writeField("caller", formatterCaller(a.Caller))
+	}
+
+	// phuslu parses a "stack" key into its own argument rather than KeyValues
+	if a.Stack != "" {
+		flattenFormatterField("stack", a.Stack, 'o', writeField)
+	}
+
 	b.WriteByte('\n')
 	return io.WriteString(w, b.String())
 }
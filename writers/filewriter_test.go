@@ -1,9 +1,12 @@
 package writers
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/phuslu/log"
 	"github.com/ternarybob/arbor/levels"
@@ -362,3 +365,201 @@ func TestFileLogEntry_Struct(t *testing.T) {
 		t.Errorf("Expected prefix 'TEST', got '%s'", entry.Prefix)
 	}
 }
+
+// readLogOutput writes an event through a new file writer and returns the file contents
+func readLogOutput(t *testing.T, outputType models.OutputFormat, event *models.LogEvent) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	writer := FileWriter(models.WriterConfiguration{
+		Type:       models.LogWriterTypeFile,
+		Level:      levels.TraceLevel,
+		FileName:   filepath.Join(dir, "typed.log"),
+		OutputType: outputType,
+	})
+
+	if err := writer.(IEventWriter).WriteEvent(event); err != nil {
+		t.Fatalf("WriteEvent should not return error: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close should not return error: %v", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "typed*.log"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("Expected a log file to be written, err: %v", err)
+	}
+
+	var content string
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("Failed to read log file: %v", err)
+		}
+		content += string(data)
+	}
+	return content
+}
+
+func typedFieldsEvent() *models.LogEvent {
+	return &models.LogEvent{
+		Level:     log.InfoLevel,
+		Timestamp: time.Now(),
+		Message:   "typed fields",
+		Fields: map[string]interface{}{
+			"at":     time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+			"big":    uint64(18446744073709551615),
+			"ids":    []int{1, 2, 3},
+			"errors": []string{"first", "second"},
+			"request": map[string]interface{}{
+				"method": "GET",
+				"status": 200,
+				"client": map[string]interface{}{"ip": "10.0.0.1"},
+			},
+		},
+	}
+}
+
+func TestFileWriter_TypedFields_Logfmt(t *testing.T) {
+	content := readLogOutput(t, models.OutputFormatLogfmt, typedFieldsEvent())
+
+	expected := []string{
+		"at=2025-01-02T03:04:05Z",
+		"big=18446744073709551615",
+		"ids=[1,2,3]",
+		"errors=[first,second]",
+		"request.method=GET",
+		"request.status=200",
+		"request.client.ip=10.0.0.1",
+	}
+	for _, want := range expected {
+		if !strings.Contains(content, want) {
+			t.Errorf("Expected logfmt output to contain %q, got: %s", want, content)
+		}
+	}
+}
+
+func TestFileWriter_TypedFields_JSON(t *testing.T) {
+	content := readLogOutput(t, models.OutputFormatJSON, typedFieldsEvent())
+
+	var decoded map[string]interface{}
+	decoder := json.NewDecoder(strings.NewReader(content))
+	decoder.UseNumber()
+	if err := decoder.Decode(&decoded); err != nil {
+		t.Fatalf("Expected valid JSON output, got error %v: %s", err, content)
+	}
+
+	request, ok := decoded["request"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected request to be a nested JSON object, got %T", decoded["request"])
+	}
+	if request["status"] != json.Number("200") {
+		t.Errorf("Expected nested status to be the number 200, got %v", request["status"])
+	}
+	if decoded["big"] != json.Number("18446744073709551615") {
+		t.Errorf("Expected uint64 to be written as a number, got %v", decoded["big"])
+	}
+	if _, ok := decoded["ids"].([]interface{}); !ok {
+		t.Errorf("Expected ids to be a JSON array, got %T", decoded["ids"])
+	}
+	if decoded["at"] != "2025-01-02T03:04:05Z" {
+		t.Errorf("Expected time to be RFC3339, got %v", decoded["at"])
+	}
+}
+
+func errorChainEvent() *models.LogEvent {
+	return &models.LogEvent{
+		Level:     log.ErrorLevel,
+		Timestamp: time.Now(),
+		Message:   "request failed",
+		Error:     "query: timeout",
+		ErrorChain: []models.ErrorDetail{
+			{Message: "query: timeout", Type: "*fmt.wrapError"},
+			{Message: "timeout", Type: "*errors.errorString"},
+		},
+		Stack: []string{"main.handler /app/main.go:42", "main.main /app/main.go:10"},
+	}
+}
+
+func TestFileWriter_ErrorChain_Logfmt(t *testing.T) {
+	content := readLogOutput(t, models.OutputFormatLogfmt, errorChainEvent())
+
+	expected := []string{
+		`error="query: timeout"`,
+		"errorchain.1.message=timeout",
+		"errorchain.1.type=*errors.errorString",
+		"stack=",
+		"main.handler /app/main.go:42",
+	}
+	for _, want := range expected {
+		if !strings.Contains(content, want) {
+			t.Errorf("Expected logfmt output to contain %q, got: %s", want, content)
+		}
+	}
+}
+
+func TestFileWriter_ErrorChain_JSON(t *testing.T) {
+	content := readLogOutput(t, models.OutputFormatJSON, errorChainEvent())
+
+	var decoded struct {
+		ErrorChain []models.ErrorDetail `json:"errorchain"`
+		Stack      []string             `json:"stack"`
+	}
+	if err := json.NewDecoder(strings.NewReader(content)).Decode(&decoded); err != nil {
+		t.Fatalf("Expected valid JSON output, got error %v: %s", err, content)
+	}
+
+	if len(decoded.ErrorChain) != 2 || decoded.ErrorChain[1].Type != "*errors.errorString" {
+		t.Errorf("Expected error chain to round trip, got %+v", decoded.ErrorChain)
+	}
+	if len(decoded.Stack) != 2 || decoded.Stack[0] != "main.handler /app/main.go:42" {
+		t.Errorf("Expected stack to round trip, got %v", decoded.Stack)
+	}
+}
+
+func TestFileWriter_Caller(t *testing.T) {
+	event := &models.LogEvent{
+		Level:     log.InfoLevel,
+		Timestamp: time.Now(),
+		Message:   "with caller",
+		Function:  "main.handler",
+		Caller:    &models.CallerInfo{File: "/app/cmd/main.go", Line: 42, Function: "main.handler"},
+	}
+
+	logfmt := readLogOutput(t, models.OutputFormatLogfmt, event)
+	if !strings.Contains(logfmt, "caller=main.go:42") {
+		t.Errorf("Expected logfmt output to contain caller=main.go:42, got: %s", logfmt)
+	}
+
+	content := readLogOutput(t, models.OutputFormatJSON, event)
+	var decoded struct {
+		Caller models.CallerInfo `json:"caller"`
+	}
+	if err := json.NewDecoder(strings.NewReader(content)).Decode(&decoded); err != nil {
+		t.Fatalf("Expected valid JSON output, got error %v: %s", err, content)
+	}
+	if decoded.Caller.File != "/app/cmd/main.go" || decoded.Caller.Line != 42 {
+		t.Errorf("Expected caller to be a JSON object with file and line, got %+v", decoded.Caller)
+	}
+}
+
+func TestFileWriter_FatalAndPanicDoNotTerminate(t *testing.T) {
+	for _, level := range []log.Level{log.FatalLevel, log.PanicLevel} {
+		event := &models.LogEvent{
+			Level:     level,
+			Timestamp: time.Now(),
+			Message:   "terminal",
+		}
+
+		// phuslu would exit or panic here; termination is left to the logger
+		content := readLogOutput(t, models.OutputFormatLogfmt, event)
+
+		expected := "level=FTL"
+		if level == log.PanicLevel {
+			expected = "level=PNC"
+		}
+		if !strings.Contains(content, expected) {
+			t.Errorf("Expected output to contain %q, got: %s", expected, content)
+		}
+	}
+}
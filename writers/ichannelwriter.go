@@ -1,7 +1,8 @@
 package writers
 
 type IChannelWriter interface {
-	IWriter
+	IEventWriter
+	IFlusher
 	Start() error
 	Stop() error
 	IsRunning() bool
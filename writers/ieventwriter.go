@@ -0,0 +1,12 @@
+package writers
+
+import "github.com/ternarybob/arbor/models"
+
+// IEventWriter is implemented by writers that accept a models.LogEvent directly.
+// The logger prefers WriteEvent over IWriter.Write, avoiding a JSON marshal/unmarshal
+// round trip per writer and preserving field types (ints stay ints).
+// Implementations must treat the event as read-only; it is shared between writers.
+type IEventWriter interface {
+	IWriter
+	WriteEvent(event *models.LogEvent) error
+}
@@ -0,0 +1,7 @@
+package writers
+
+// IFlusher is implemented by writers and stores that buffer events asynchronously.
+// Flush blocks until every event accepted before the call has been processed.
+type IFlusher interface {
+	Flush() error
+}
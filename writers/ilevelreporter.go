@@ -0,0 +1,16 @@
+package writers
+
+import "github.com/phuslu/log"
+
+// ILevelReporter is implemented by writers that can report their current minimum level
+type ILevelReporter interface {
+	GetLevel() log.Level
+}
+
+// WriterLevel returns the level reported by writer, or 0 if it does not report one
+func WriterLevel(writer IWriter) log.Level {
+	if reporter, ok := writer.(ILevelReporter); ok {
+		return reporter.GetLevel()
+	}
+	return 0
+}
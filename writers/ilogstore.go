@@ -36,3 +36,11 @@ type ILogStore interface {
 	// Close cleans up resources
 	Close() error
 }
+
+// IPersistentLogStore is implemented by log stores that can persist entries to a database
+type IPersistentLogStore interface {
+	ILogStore
+
+	// GetDBPath returns the configured database path, or "" when entries are not persisted
+	GetDBPath() string
+}
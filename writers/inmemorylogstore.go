@@ -33,15 +33,23 @@ type inMemoryLogStore struct {
 	entries    map[string][]models.LogEvent // correlationID -> events
 	allEntries []models.LogEvent            // All entries for timestamp queries
 	entriesMux sync.RWMutex
+	closed     bool // Set by Close under entriesMux; no entry is queued for persistence after it
+
+	// Channel writers feeding the store, stopped by Close before persistence shuts down
+	feeders    []IChannelWriter
+	feedersMux sync.Mutex
 
 	// Configuration
 	ttl               time.Duration
 	enablePersistence bool
+	configuredDBPath  string // DBPath as configured, before a database file name is added
 	dbPath            string
 
 	// Optional BoltDB persistence
-	db            *bbolt.DB
-	persistBuffer chan models.LogEvent
+	db             *bbolt.DB
+	persistBuffer  chan models.LogEvent
+	persistFlushes chan chan struct{}
+	persistDone    chan struct{} // closed when the persistence worker exits
 
 	// Cleanup
 	cleanupTicker *time.Ticker
@@ -57,8 +65,22 @@ type StoredLogEntry struct {
 	ExpiresAt time.Time       `json:"expires_at"`
 }
 
-// NewInMemoryLogStore creates a new in-memory log store
+// NewInMemoryLogStore creates a new in-memory log store.
+// If the BoltDB at config.DBPath cannot be opened, the store continues in memory only.
 func NewInMemoryLogStore(config models.WriterConfiguration) (ILogStore, error) {
+	return newInMemoryLogStore(config, false)
+}
+
+// NewPersistentLogStore creates an in-memory log store persisted to the BoltDB at config.DBPath,
+// returning an error rather than continuing in memory only if the database cannot be opened
+func NewPersistentLogStore(config models.WriterConfiguration) (ILogStore, error) {
+	if config.DBPath == "" {
+		return nil, fmt.Errorf("dbpath is required for a persistent log store")
+	}
+	return newInMemoryLogStore(config, true)
+}
+
+func newInMemoryLogStore(config models.WriterConfiguration, requirePersistence bool) (ILogStore, error) {
 	internalLog := common.NewLogger().WithContext("function", "NewInMemoryLogStore").GetLogger()
 
 	store := &inMemoryLogStore{
@@ -66,8 +88,11 @@ func NewInMemoryLogStore(config models.WriterConfiguration) (ILogStore, error) {
 		allEntries:        make([]models.LogEvent, 0),
 		ttl:               DEFAULT_TTL,
 		enablePersistence: config.DBPath != "",
+		configuredDBPath:  config.DBPath,
 		dbPath:            config.DBPath,
 		persistBuffer:     make(chan models.LogEvent, 1000),
+		persistFlushes:    make(chan chan struct{}),
+		persistDone:       make(chan struct{}),
 		cleanupStop:       make(chan bool),
 		indexCounter:      0,
 	}
@@ -75,6 +100,9 @@ func NewInMemoryLogStore(config models.WriterConfiguration) (ILogStore, error) {
 	// Initialize BoltDB if persistence is enabled
 	if store.enablePersistence {
 		if err := store.initPersistence(); err != nil {
+			if requirePersistence {
+				return nil, err
+			}
 			internalLog.Error().Err(err).Msg("Failed to initialize persistence, continuing in-memory only")
 			store.enablePersistence = false
 		} else {
@@ -90,6 +118,14 @@ func NewInMemoryLogStore(config models.WriterConfiguration) (ILogStore, error) {
 	return store, nil
 }
 
+// GetDBPath returns the configured database path, or "" when entries are not persisted
+func (s *inMemoryLogStore) GetDBPath() string {
+	if !s.enablePersistence {
+		return ""
+	}
+	return s.configuredDBPath
+}
+
 // initPersistence sets up BoltDB
 func (s *inMemoryLogStore) initPersistence() error {
 	// Create date-based database filename if not fully specified
@@ -129,11 +165,52 @@ func (s *inMemoryLogStore) initPersistence() error {
 
 // persistWorker handles async writes to BoltDB
 func (s *inMemoryLogStore) persistWorker() {
-	for entry := range s.persistBuffer {
-		s.persistToDB(entry)
+	defer close(s.persistDone)
+
+	for {
+		select {
+		case entry, ok := <-s.persistBuffer:
+			if !ok {
+				return
+			}
+			s.persistToDB(entry)
+		case flushed := <-s.persistFlushes:
+			// Persist everything queued before the flush request
+			for drained := false; !drained; {
+				select {
+				case entry, ok := <-s.persistBuffer:
+					if !ok {
+						close(flushed)
+						return
+					}
+					s.persistToDB(entry)
+				default:
+					drained = true
+				}
+			}
+			close(flushed)
+		}
 	}
 }
 
+// Flush blocks until entries queued for BoltDB persistence have been written.
+// Returns immediately when persistence is disabled or the store is closed.
+func (s *inMemoryLogStore) Flush() error {
+	if !s.enablePersistence {
+		return nil
+	}
+
+	flushed := make(chan struct{})
+	select {
+	case s.persistFlushes <- flushed:
+	case <-s.persistDone:
+		return nil
+	}
+
+	<-flushed
+	return nil
+}
+
 // persistToDB writes a single entry to BoltDB
 func (s *inMemoryLogStore) persistToDB(entry models.LogEvent) error {
 	if s.db == nil {
@@ -163,22 +240,21 @@ func (s *inMemoryLogStore) persistToDB(entry models.LogEvent) error {
 
 // Store adds a log entry to in-memory store and optionally persists to BoltDB
 func (s *inMemoryLogStore) Store(entry models.LogEvent) error {
-	// Assign index
 	s.entriesMux.Lock()
+	defer s.entriesMux.Unlock()
+
+	// Assign index
 	s.indexCounter++
 	entry.Index = s.indexCounter
-	s.entriesMux.Unlock()
 
 	// Store in memory (fast, primary storage)
-	s.entriesMux.Lock()
 	if entry.CorrelationID != "" {
 		s.entries[entry.CorrelationID] = append(s.entries[entry.CorrelationID], entry)
 	}
 	s.allEntries = append(s.allEntries, entry)
-	s.entriesMux.Unlock()
 
-	// Async persist to BoltDB if enabled (non-blocking)
-	if s.enablePersistence {
+	// Async persist to BoltDB if enabled (non-blocking); the buffer is closed once the store is
+	if s.enablePersistence && !s.closed {
 		select {
 		case s.persistBuffer <- entry:
 			// Buffered successfully
@@ -400,9 +476,38 @@ func (s *inMemoryLogStore) cleanupBoltDB(cutoff time.Time) {
 	}
 }
 
-// Close shuts down the log store
+// addFeeder records a channel writer that stores entries in the store, so Close can stop it first
+func (s *inMemoryLogStore) addFeeder(feeder IChannelWriter) {
+	s.feedersMux.Lock()
+	s.feeders = append(s.feeders, feeder)
+	s.feedersMux.Unlock()
+}
+
+// removeFeeder forgets a channel writer that has been closed
+func (s *inMemoryLogStore) removeFeeder(feeder IChannelWriter) {
+	s.feedersMux.Lock()
+	defer s.feedersMux.Unlock()
+
+	for i, existing := range s.feeders {
+		if existing == feeder {
+			s.feeders = append(s.feeders[:i], s.feeders[i+1:]...)
+			return
+		}
+	}
+}
+
+// Close shuts down the log store. Channel writers still feeding it are stopped first, so the
+// entries they have queued are stored and persisted.
 func (s *inMemoryLogStore) Close() error {
 	s.closeOnce.Do(func() {
+		s.feedersMux.Lock()
+		feeders := s.feeders
+		s.feeders = nil
+		s.feedersMux.Unlock()
+		for _, feeder := range feeders {
+			feeder.Stop()
+		}
+
 		// Stop cleanup
 		if s.cleanupTicker != nil {
 			s.cleanupTicker.Stop()
@@ -411,10 +516,17 @@ func (s *inMemoryLogStore) Close() error {
 			close(s.cleanupStop)
 		}
 
-		// Close persist buffer
+		// Close persist buffer and wait for queued entries to be written
+		s.entriesMux.Lock()
+		s.closed = true
 		if s.persistBuffer != nil {
 			close(s.persistBuffer)
 		}
+		s.entriesMux.Unlock()
+
+		if s.enablePersistence {
+			<-s.persistDone
+		}
 
 		// Close BoltDB
 		if s.db != nil {
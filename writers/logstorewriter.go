@@ -42,7 +42,12 @@ func LogStoreWriter(store ILogStore, config models.WriterConfiguration) IWriter
 		writer: writer,
 	}
 
-	return lsw
+	// The store stops the writer when it is closed first, so no entry arrives after it shut down
+	if memoryStore, ok := store.(*inMemoryLogStore); ok {
+		memoryStore.addFeeder(writer)
+	}
+
+	return withDedup(lsw, config)
 }
 
 // Write implements IWriter interface
@@ -50,6 +55,22 @@ func (lsw *logStoreWriter) Write(data []byte) (int, error) {
 	return lsw.writer.Write(data)
 }
 
+// WriteEvent queues the event for the store without a JSON round trip
+func (lsw *logStoreWriter) WriteEvent(logEvent *models.LogEvent) error {
+	return lsw.writer.WriteEvent(logEvent)
+}
+
+// Flush blocks until queued events have been stored, including any persistence queued by the store
+func (lsw *logStoreWriter) Flush() error {
+	if err := lsw.writer.Flush(); err != nil {
+		return err
+	}
+	if flusher, ok := lsw.store.(IFlusher); ok {
+		return flusher.Flush()
+	}
+	return nil
+}
+
 // WithLevel sets the minimum log level for this writer
 func (lsw *logStoreWriter) WithLevel(level log.Level) IWriter {
 	lsw.writer.WithLevel(level)
@@ -57,6 +78,11 @@ func (lsw *logStoreWriter) WithLevel(level log.Level) IWriter {
 	return lsw
 }
 
+// GetLevel returns the writer's minimum level
+func (lsw *logStoreWriter) GetLevel() log.Level {
+	return WriterLevel(lsw.writer)
+}
+
 // GetFilePath returns empty string as store doesn't write to files directly
 func (lsw *logStoreWriter) GetFilePath() string {
 	return lsw.writer.GetFilePath()
@@ -64,5 +90,8 @@ func (lsw *logStoreWriter) GetFilePath() string {
 
 // Close shuts down the writer
 func (lsw *logStoreWriter) Close() error {
+	if memoryStore, ok := lsw.store.(*inMemoryLogStore); ok {
+		memoryStore.removeFeeder(lsw.writer)
+	}
 	return lsw.writer.Close()
 }
@@ -8,6 +8,8 @@ package writers
 import (
 	"fmt"
 	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/phuslu/log"
@@ -19,8 +21,9 @@ import (
 // memoryWriter provides query interface to the log store
 // It doesn't write directly - LogStoreWriter handles that
 type memoryWriter struct {
-	store  ILogStore
-	config models.WriterConfiguration
+	store     ILogStore
+	config    models.WriterConfiguration
+	configMux sync.RWMutex
 }
 
 // MemoryWriter creates a new memory writer backed by a log store
@@ -35,12 +38,16 @@ func MemoryWriter(config models.WriterConfiguration) IMemoryWriter {
 		internalLog.Fatal().Err(err).Msg("Failed to create log store")
 	}
 
-	mw := &memoryWriter{
+	return MemoryWriterWithStore(store, config)
+}
+
+// MemoryWriterWithStore creates a memory writer that queries an existing log store.
+// Closing the writer closes the store.
+func MemoryWriterWithStore(store ILogStore, config models.WriterConfiguration) IMemoryWriter {
+	return &memoryWriter{
 		store:  store,
 		config: config,
 	}
-
-	return mw
 }
 
 // GetStore returns the underlying log store for use with LogStoreWriter
@@ -54,12 +61,35 @@ func (mw *memoryWriter) Write(data []byte) (int, error) {
 	return len(data), nil
 }
 
+// WriteEvent is a no-op for the memory writer
+// Use LogStoreWriter for actual writing
+func (mw *memoryWriter) WriteEvent(logEvent *models.LogEvent) error {
+	return nil
+}
+
+// Flush blocks until the store has persisted queued entries
+func (mw *memoryWriter) Flush() error {
+	if flusher, ok := mw.store.(IFlusher); ok {
+		return flusher.Flush()
+	}
+	return nil
+}
+
 // WithLevel sets the log level (no-op for memory writer, filtering done at query time)
 func (mw *memoryWriter) WithLevel(level log.Level) IWriter {
+	mw.configMux.Lock()
 	mw.config.Level = levels.FromLogLevel(level)
+	mw.configMux.Unlock()
 	return mw
 }
 
+// GetLevel returns the level set with WithLevel
+func (mw *memoryWriter) GetLevel() log.Level {
+	mw.configMux.RLock()
+	defer mw.configMux.RUnlock()
+	return mw.config.Level.ToLogLevel()
+}
+
 // GetFilePath returns empty string as memory writer doesn't write to files
 func (mw *memoryWriter) GetFilePath() string {
 	return ""
@@ -247,6 +277,29 @@ func formatLogEvent(l *models.LogEvent) string {
 		output += "|" + l.Error
 	}
 
+	// Wrapped causes follow the error, outermost first
+	if len(l.ErrorChain) > 1 {
+		causes := make([]string, 0, len(l.ErrorChain)-1)
+		for _, cause := range l.ErrorChain[1:] {
+			causes = append(causes, cause.Message+" ("+cause.Type+")")
+		}
+		output += "|caused by: " + strings.Join(causes, " <- ")
+	}
+
+	if len(l.Stack) > 0 {
+		output += "|stack: " + strings.Join(l.Stack, ", ")
+	}
+
+	if len(l.Fields) > 0 {
+		pairs := make([]string, 0, len(l.Fields))
+		for _, key := range sortedKeys(l.Fields) {
+			flattenField(key, l.Fields[key], func(key, value string) {
+				pairs = append(pairs, key+"="+value)
+			})
+		}
+		output += "|" + strings.Join(pairs, " ")
+	}
+
 	return output
 }
 
@@ -7,12 +7,14 @@ package writers
 
 import (
 	"encoding/json"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/phuslu/log"
 	"github.com/ternarybob/arbor/levels"
 	"github.com/ternarybob/arbor/models"
+	"go.etcd.io/bbolt"
 )
 
 func TestMemoryWriter_Basic(t *testing.T) {
@@ -274,3 +276,161 @@ func TestMemoryWriter_WithPersistence(t *testing.T) {
 		t.Error("Expected at least one entry")
 	}
 }
+
+func TestMemoryWriter_TypedFieldsFormatting(t *testing.T) {
+	config := models.WriterConfiguration{
+		Type:  models.LogWriterTypeMemory,
+		Level: levels.LogLevel(log.TraceLevel),
+	}
+
+	memWriter := MemoryWriter(config)
+	defer memWriter.Close()
+
+	store := memWriter.GetStore()
+	storeWriter := LogStoreWriter(store, config).(IEventWriter)
+	defer storeWriter.Close()
+
+	correlationID := "typed-fields-123"
+	logEvent := &models.LogEvent{
+		Level:         log.InfoLevel,
+		Timestamp:     time.Now(),
+		CorrelationID: correlationID,
+		Message:       "typed",
+		Fields: map[string]interface{}{
+			"ids":     []int{1, 2},
+			"at":      time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+			"request": map[string]interface{}{"status": 200},
+		},
+	}
+	if err := storeWriter.WriteEvent(logEvent); err != nil {
+		t.Fatalf("WriteEvent should not return error: %v", err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	entries, err := store.GetByCorrelation(correlationID)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("Expected 1 stored entry, got %d (err: %v)", len(entries), err)
+	}
+	if _, ok := entries[0].Fields["at"].(time.Time); !ok {
+		t.Errorf("Expected time field to be stored as time.Time, got %T", entries[0].Fields["at"])
+	}
+
+	formatted, err := memWriter.GetEntries(correlationID)
+	if err != nil {
+		t.Fatalf("GetEntries should not return error: %v", err)
+	}
+	for _, line := range formatted {
+		expected := "|at=2025-01-02T03:04:05Z ids=[1,2] request.status=200"
+		if len(line) < len(expected) || line[len(line)-len(expected):] != expected {
+			t.Errorf("Expected formatted entry to end with %q, got %q", expected, line)
+		}
+	}
+}
+
+func TestFormatLogEvent_ErrorChainAndStack(t *testing.T) {
+	formatted := formatLogEvent(&models.LogEvent{
+		Level:     log.ErrorLevel,
+		Timestamp: time.Now(),
+		Message:   "failed",
+		Error:     "query: timeout",
+		ErrorChain: []models.ErrorDetail{
+			{Message: "query: timeout", Type: "*fmt.wrapError"},
+			{Message: "timeout", Type: "*errors.errorString"},
+		},
+		Stack: []string{"main.handler /app/main.go:42", "main.main /app/main.go:10"},
+	})
+
+	expected := "|failed|query: timeout|caused by: timeout (*errors.errorString)|stack: main.handler /app/main.go:42, main.main /app/main.go:10"
+	if len(formatted) < len(expected) || formatted[len(formatted)-len(expected):] != expected {
+		t.Errorf("Expected formatted entry to end with %q, got %q", expected, formatted)
+	}
+}
+
+func TestLogStoreWriter_FlushPersistsToBoltDB(t *testing.T) {
+	config := models.WriterConfiguration{
+		Type:   models.LogWriterTypeMemory,
+		Level:  levels.LogLevel(log.TraceLevel),
+		DBPath: filepath.Join(t.TempDir(), "flush_logs"),
+	}
+
+	memWriter := MemoryWriter(config)
+	defer memWriter.Close()
+
+	store := memWriter.GetStore()
+	storeWriter := LogStoreWriter(store, config)
+	defer storeWriter.Close()
+
+	for i := 0; i < 10; i++ {
+		event := createTestLogEvent(log.InfoLevel, "flush-persist", "persisted")
+		storeWriter.(IEventWriter).WriteEvent(&event)
+	}
+
+	if err := storeWriter.(IFlusher).Flush(); err != nil {
+		t.Fatalf("Flush should not return error: %v", err)
+	}
+
+	db := store.(*inMemoryLogStore).db
+	if db == nil {
+		t.Fatal("Expected BoltDB persistence to be enabled")
+	}
+
+	var persisted int
+	db.View(func(tx *bbolt.Tx) error {
+		persisted = tx.Bucket([]byte(LOG_BUCKET)).Stats().KeyN
+		return nil
+	})
+	if persisted != 10 {
+		t.Errorf("Expected 10 entries persisted after Flush(), got %d", persisted)
+	}
+}
+
+func TestInMemoryLogStore_CloseWhileStoring(t *testing.T) {
+	config := models.WriterConfiguration{
+		Type:   models.LogWriterTypeMemory,
+		Level:  levels.LogLevel(log.TraceLevel),
+		DBPath: filepath.Join(t.TempDir(), "close_logs"),
+	}
+
+	memWriter := MemoryWriter(config)
+	store := memWriter.GetStore()
+	storeWriter := LogStoreWriter(store, config)
+	defer storeWriter.Close()
+
+	for i := 0; i < 100; i++ {
+		event := createTestLogEvent(log.InfoLevel, "close-race", "queued")
+		storeWriter.(IEventWriter).WriteEvent(&event)
+	}
+
+	// Keep writing while the store closes
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				event := createTestLogEvent(log.InfoLevel, "close-race", "racing")
+				storeWriter.(IEventWriter).WriteEvent(&event)
+			}
+		}
+	}()
+
+	if err := memWriter.Close(); err != nil {
+		t.Errorf("Close should not return error: %v", err)
+	}
+	close(stop)
+	<-done
+
+	// Entries stored after Close stay in memory only
+	if err := store.Store(createTestLogEvent(log.InfoLevel, "close-race", "after close")); err != nil {
+		t.Errorf("Store after Close should not return error: %v", err)
+	}
+
+	entries, _ := store.GetByCorrelation("close-race")
+	if len(entries) < 100 {
+		t.Errorf("Expected the entries queued before Close to be stored, got %d", len(entries))
+	}
+}
@@ -0,0 +1,550 @@
+package writers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/phuslu/log"
+	"github.com/ternarybob/arbor/common"
+	"github.com/ternarybob/arbor/levels"
+	"github.com/ternarybob/arbor/models"
+)
+
+// OTLP export defaults
+const (
+	DefaultOTLPEndpoint      = "http://localhost:4318/v1/logs"
+	DefaultOTLPBatchSize     = 512
+	DefaultOTLPFlushInterval = 5 * time.Second
+	DefaultOTLPQueueSize     = 2048
+	DefaultOTLPTimeout       = 10 * time.Second
+	DefaultOTLPMaxRetries    = 3
+	DefaultOTLPRetryBackoff  = 500 * time.Millisecond
+)
+
+// otlpScopeName identifies arbor as the instrumentation scope of exported records
+const otlpScopeName = "github.com/ternarybob/arbor"
+
+// OTelSeverity maps an arbor level to an OpenTelemetry severity number and text:
+// trace 1, debug 5, info 9, warn 13, error 17, fatal 21 and panic 22.
+func OTelSeverity(level log.Level) (int, string) {
+	switch level {
+	case log.TraceLevel:
+		return 1, "TRACE"
+	case log.DebugLevel:
+		return 5, "DEBUG"
+	case log.InfoLevel:
+		return 9, "INFO"
+	case log.WarnLevel:
+		return 13, "WARN"
+	case log.ErrorLevel:
+		return 17, "ERROR"
+	case log.FatalLevel:
+		return 21, "FATAL"
+	case log.PanicLevel:
+		return 22, "PANIC"
+	default:
+		return 0, ""
+	}
+}
+
+// otlpWriter batches events and exports them to an OpenTelemetry collector
+type otlpWriter struct {
+	config    models.OTLPConfiguration
+	configMux sync.RWMutex
+	client    *http.Client
+	resource  otlpResource
+	queue     chan models.LogEvent
+	flushes   chan chan error
+	done      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// OTLPWriter creates a writer that exports events to an OpenTelemetry collector using OTLP/HTTP
+// with JSON encoding. Events are queued and sent in batches of config.BatchSize, or every
+// config.FlushInterval. Failed requests are retried with exponential backoff when the
+// collector is unreachable or answers 429, 502, 503 or 504; other failures drop the batch.
+// Events are dropped when the queue is full, so logging never blocks on the collector.
+func OTLPWriter(config models.OTLPConfiguration) IWriter {
+	if config.Endpoint == "" {
+		config.Endpoint = DefaultOTLPEndpoint
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultOTLPBatchSize
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = DefaultOTLPFlushInterval
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = DefaultOTLPQueueSize
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultOTLPTimeout
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = DefaultOTLPMaxRetries
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = DefaultOTLPRetryBackoff
+	}
+
+	client := config.Client
+	if client == nil {
+		client = &http.Client{Timeout: config.Timeout}
+	}
+
+	ow := &otlpWriter{
+		config:   config,
+		client:   client,
+		resource: newOTLPResource(config),
+		queue:    make(chan models.LogEvent, config.QueueSize),
+		flushes:  make(chan chan error),
+		done:     make(chan struct{}),
+	}
+
+	ow.wg.Add(1)
+	go ow.run()
+
+	return ow
+}
+
+// run collects queued events into batches and sends them until the writer is closed
+func (ow *otlpWriter) run() {
+	defer ow.wg.Done()
+
+	ticker := time.NewTicker(ow.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]models.LogEvent, 0, ow.config.BatchSize)
+	send := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := ow.export(batch)
+		batch = batch[:0]
+		return err
+	}
+	drain := func() error {
+		var errs []error
+		for {
+			select {
+			case event := <-ow.queue:
+				batch = append(batch, event)
+				if len(batch) >= ow.config.BatchSize {
+					errs = append(errs, send())
+				}
+			default:
+				errs = append(errs, send())
+				return errors.Join(errs...)
+			}
+		}
+	}
+
+	for {
+		select {
+		case event := <-ow.queue:
+			batch = append(batch, event)
+			if len(batch) >= ow.config.BatchSize {
+				send()
+			}
+		case <-ticker.C:
+			send()
+		case flushed := <-ow.flushes:
+			flushed <- drain()
+		case <-ow.done:
+			drain()
+			return
+		}
+	}
+}
+
+// export sends a batch, retrying transient failures. A batch that cannot be sent is dropped.
+func (ow *otlpWriter) export(batch []models.LogEvent) error {
+	internalLog := common.NewLogger().WithContext("function", "otlpWriter.export").GetLogger()
+
+	body, err := json.Marshal(ow.newRequest(batch))
+	if err != nil {
+		internalLog.Warn().Err(err).Msgf("Failed to encode %d event(s) for OTLP export, dropping them", len(batch))
+		return err
+	}
+
+	backoff := ow.config.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		retryAfter, err := ow.post(body)
+		if err == nil {
+			return nil
+		}
+
+		var permanent *otlpPermanentError
+		if errors.As(err, &permanent) || attempt >= ow.config.MaxRetries {
+			internalLog.Warn().Err(err).Msgf("OTLP export failed after %d attempt(s), dropping %d event(s)", attempt+1, len(batch))
+			return err
+		}
+
+		wait := backoff
+		if retryAfter > 0 {
+			wait = retryAfter
+		}
+		backoff *= 2
+
+		select {
+		case <-time.After(wait):
+		case <-ow.done:
+			// Closing: make one last attempt without waiting
+		}
+	}
+}
+
+// otlpPermanentError is a response that retrying will not fix
+type otlpPermanentError struct {
+	status string
+	body   string
+}
+
+func (e *otlpPermanentError) Error() string {
+	return fmt.Sprintf("OTLP collector rejected the export: %s %s", e.status, e.body)
+}
+
+// post sends one request, returning the delay requested by a Retry-After header
+func (ow *otlpWriter) post(body []byte) (time.Duration, error) {
+	request, err := http.NewRequest(http.MethodPost, ow.config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return 0, &otlpPermanentError{status: err.Error()}
+	}
+	request.Header.Set("Content-Type", "application/json")
+	for key, value := range ow.config.Headers {
+		request.Header.Set(key, value)
+	}
+
+	response, err := ow.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	detail, _ := io.ReadAll(io.LimitReader(response.Body, 512))
+
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return 0, nil
+	}
+
+	switch response.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		var retryAfter time.Duration
+		if seconds, err := strconv.Atoi(response.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			retryAfter = time.Duration(seconds) * time.Second
+		}
+		return retryAfter, fmt.Errorf("OTLP collector unavailable: %s", response.Status)
+	default:
+		return 0, &otlpPermanentError{status: response.Status, body: strings.TrimSpace(string(detail))}
+	}
+}
+
+func (ow *otlpWriter) Write(data []byte) (int, error) {
+	n := len(data)
+	if n == 0 {
+		return n, nil
+	}
+
+	var logEvent models.LogEvent
+	if err := json.Unmarshal(data, &logEvent); err != nil {
+		return 0, err
+	}
+
+	ow.WriteEvent(&logEvent)
+	return n, nil
+}
+
+// WriteEvent filters the event by level and queues a copy for export
+func (ow *otlpWriter) WriteEvent(logEvent *models.LogEvent) error {
+	if logEvent.Level < ow.GetLevel() {
+		return nil
+	}
+
+	select {
+	case <-ow.done:
+		return nil
+	default:
+	}
+
+	select {
+	case ow.queue <- *logEvent:
+	default:
+		internalLog := common.NewLogger().WithContext("function", "otlpWriter.WriteEvent").GetLogger()
+		internalLog.Warn().Msg("OTLP export queue full, dropping entry")
+	}
+	return nil
+}
+
+// Flush exports every event queued before the call, returning the error of any failed batch
+func (ow *otlpWriter) Flush() error {
+	flushed := make(chan error, 1)
+	select {
+	case ow.flushes <- flushed:
+	case <-ow.done:
+		return nil
+	}
+
+	select {
+	case err := <-flushed:
+		return err
+	case <-ow.done:
+		return nil
+	}
+}
+
+func (ow *otlpWriter) WithLevel(level log.Level) IWriter {
+	ow.configMux.Lock()
+	ow.config.Level = levels.FromLogLevel(level)
+	ow.configMux.Unlock()
+	return ow
+}
+
+// GetLevel returns the writer's minimum level
+func (ow *otlpWriter) GetLevel() log.Level {
+	ow.configMux.RLock()
+	defer ow.configMux.RUnlock()
+	return ow.config.Level.ToLogLevel()
+}
+
+func (ow *otlpWriter) GetFilePath() string {
+	return ""
+}
+
+// Close exports the queued events and stops the writer
+func (ow *otlpWriter) Close() error {
+	ow.closeOnce.Do(func() {
+		close(ow.done)
+	})
+	ow.wg.Wait()
+	return nil
+}
+
+// OTLP/JSON request body, see https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding
+
+type otlpLogsRequest struct {
+	ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+}
+
+type otlpResourceLogs struct {
+	Resource  otlpResource    `json:"resource"`
+	ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpScopeLogs struct {
+	Scope      otlpScope       `json:"scope"`
+	LogRecords []otlpLogRecord `json:"logRecords"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano         string         `json:"timeUnixNano"`
+	ObservedTimeUnixNano string         `json:"observedTimeUnixNano"`
+	SeverityNumber       int            `json:"severityNumber,omitempty"`
+	SeverityText         string         `json:"severityText,omitempty"`
+	Body                 otlpAnyValue   `json:"body"`
+	Attributes           []otlpKeyValue `json:"attributes,omitempty"`
+	TraceID              string         `json:"traceId,omitempty"`
+	SpanID               string         `json:"spanId,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string          `json:"stringValue,omitempty"`
+	BoolValue   *bool            `json:"boolValue,omitempty"`
+	IntValue    *string          `json:"intValue,omitempty"` // int64 is a string in OTLP/JSON
+	DoubleValue *float64         `json:"doubleValue,omitempty"`
+	ArrayValue  *otlpArrayValue  `json:"arrayValue,omitempty"`
+	KvlistValue *otlpKvlistValue `json:"kvlistValue,omitempty"`
+}
+
+type otlpArrayValue struct {
+	Values []otlpAnyValue `json:"values"`
+}
+
+type otlpKvlistValue struct {
+	Values []otlpKeyValue `json:"values"`
+}
+
+// newOTLPResource describes the service, defaulting its name as the OpenTelemetry SDKs do
+func newOTLPResource(config models.OTLPConfiguration) otlpResource {
+	serviceName := config.ServiceName
+	if serviceName == "" {
+		serviceName = "unknown_service:" + filepath.Base(os.Args[0])
+	}
+
+	attributes := []otlpKeyValue{otlpString("service.name", serviceName)}
+	keys := make([]string, 0, len(config.ResourceAttributes))
+	for key := range config.ResourceAttributes {
+		if key != "service.name" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		attributes = append(attributes, otlpString(key, config.ResourceAttributes[key]))
+	}
+	return otlpResource{Attributes: attributes}
+}
+
+// newRequest converts a batch of events to an OTLP export request
+func (ow *otlpWriter) newRequest(batch []models.LogEvent) otlpLogsRequest {
+	observed := strconv.FormatInt(time.Now().UnixNano(), 10)
+	records := make([]otlpLogRecord, 0, len(batch))
+	for i := range batch {
+		records = append(records, newOTLPLogRecord(&batch[i], observed))
+	}
+
+	return otlpLogsRequest{ResourceLogs: []otlpResourceLogs{{
+		Resource: ow.resource,
+		ScopeLogs: []otlpScopeLogs{{
+			Scope:      otlpScope{Name: otlpScopeName},
+			LogRecords: records,
+		}},
+	}}}
+}
+
+// newOTLPLogRecord maps an event to a log record. Arbor's own values use OpenTelemetry
+// semantic convention names where one exists; event fields keep their keys.
+func newOTLPLogRecord(logEvent *models.LogEvent, observed string) otlpLogRecord {
+	severityNumber, severityText := OTelSeverity(logEvent.Level)
+	record := otlpLogRecord{
+		TimeUnixNano:         strconv.FormatInt(logEvent.Timestamp.UnixNano(), 10),
+		ObservedTimeUnixNano: observed,
+		SeverityNumber:       severityNumber,
+		SeverityText:         severityText,
+		Body:                 otlpValue(logEvent.Message),
+		TraceID:              logEvent.TraceID,
+		SpanID:               logEvent.SpanID,
+	}
+
+	if logEvent.CorrelationID != "" {
+		record.Attributes = append(record.Attributes, otlpString("correlationid", logEvent.CorrelationID))
+	}
+	if logEvent.Prefix != "" {
+		record.Attributes = append(record.Attributes, otlpString("prefix", logEvent.Prefix))
+	}
+	if logEvent.Caller != nil {
+		record.Attributes = append(record.Attributes,
+			otlpString("code.function.name", logEvent.Caller.Function),
+			otlpString("code.file.path", logEvent.Caller.File),
+			otlpKeyValue{Key: "code.line.number", Value: otlpValue(logEvent.Caller.Line)},
+		)
+	}
+	if logEvent.Error != "" {
+		record.Attributes = append(record.Attributes, otlpString("exception.message", logEvent.Error))
+		if len(logEvent.ErrorChain) > 0 {
+			record.Attributes = append(record.Attributes, otlpString("exception.type", logEvent.ErrorChain[0].Type))
+		}
+	}
+	if len(logEvent.Stack) > 0 {
+		record.Attributes = append(record.Attributes, otlpString("exception.stacktrace", strings.Join(logEvent.Stack, "\n")))
+	}
+
+	keys := make([]string, 0, len(logEvent.Fields))
+	for key := range logEvent.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		record.Attributes = append(record.Attributes, otlpKeyValue{Key: key, Value: otlpValue(logEvent.Fields[key])})
+	}
+
+	return record
+}
+
+func otlpString(key, value string) otlpKeyValue {
+	return otlpKeyValue{Key: key, Value: otlpAnyValue{StringValue: &value}}
+}
+
+// otlpValue converts a field value to an OTLP value; unsupported types are rendered as strings
+func otlpValue(value interface{}) otlpAnyValue {
+	switch v := value.(type) {
+	case nil:
+		return otlpAnyValue{}
+	case string:
+		return otlpAnyValue{StringValue: &v}
+	case bool:
+		return otlpAnyValue{BoolValue: &v}
+	case time.Time:
+		text := v.Format(time.RFC3339Nano)
+		return otlpAnyValue{StringValue: &text}
+	case time.Duration:
+		text := v.String()
+		return otlpAnyValue{StringValue: &text}
+	case error:
+		text := v.Error()
+		return otlpAnyValue{StringValue: &text}
+	case fmt.Stringer:
+		text := v.String()
+		return otlpAnyValue{StringValue: &text}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			text := strconv.FormatInt(i, 10)
+			return otlpAnyValue{IntValue: &text}
+		}
+		text := v.String()
+		return otlpAnyValue{StringValue: &text}
+	}
+
+	reflected := reflect.ValueOf(value)
+	switch reflected.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		text := strconv.FormatInt(reflected.Int(), 10)
+		return otlpAnyValue{IntValue: &text}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		text := strconv.FormatUint(reflected.Uint(), 10)
+		return otlpAnyValue{IntValue: &text}
+	case reflect.Float32, reflect.Float64:
+		f := reflected.Float()
+		return otlpAnyValue{DoubleValue: &f}
+	case reflect.Slice, reflect.Array:
+		if reflected.Type().Elem().Kind() == reflect.Uint8 {
+			break
+		}
+		values := make([]otlpAnyValue, reflected.Len())
+		for i := range values {
+			values[i] = otlpValue(reflected.Index(i).Interface())
+		}
+		return otlpAnyValue{ArrayValue: &otlpArrayValue{Values: values}}
+	case reflect.Map:
+		if reflected.Type().Key().Kind() != reflect.String {
+			break
+		}
+		keys := make([]string, 0, reflected.Len())
+		for _, key := range reflected.MapKeys() {
+			keys = append(keys, key.String())
+		}
+		sort.Strings(keys)
+		values := make([]otlpKeyValue, 0, len(keys))
+		for _, key := range keys {
+			values = append(values, otlpKeyValue{Key: key, Value: otlpValue(reflected.MapIndex(reflect.ValueOf(key).Convert(reflected.Type().Key())).Interface())})
+		}
+		return otlpAnyValue{KvlistValue: &otlpKvlistValue{Values: values}}
+	}
+
+	text := fmt.Sprint(value)
+	return otlpAnyValue{StringValue: &text}
+}
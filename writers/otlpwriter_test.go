@@ -0,0 +1,318 @@
+package writers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/phuslu/log"
+	"github.com/ternarybob/arbor/levels"
+	"github.com/ternarybob/arbor/models"
+)
+
+// collectorStub is an in-process OTLP/HTTP collector that records the requests it accepts
+type collectorStub struct {
+	server   *httptest.Server
+	mu       sync.Mutex
+	requests []otlpLogsRequest
+	headers  []http.Header
+	attempts atomic.Int32
+	respond  func(attempt int32) int // Status code for each attempt; 200 if nil
+}
+
+func newCollectorStub(t *testing.T, respond func(attempt int32) int) *collectorStub {
+	stub := &collectorStub{respond: respond}
+	stub.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempt := stub.attempts.Add(1)
+		if stub.respond != nil {
+			if status := stub.respond(attempt); status != http.StatusOK {
+				w.WriteHeader(status)
+				return
+			}
+		}
+
+		var request otlpLogsRequest
+		if r.URL.Path != "/v1/logs" || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		stub.mu.Lock()
+		stub.requests = append(stub.requests, request)
+		stub.headers = append(stub.headers, r.Header.Clone())
+		stub.mu.Unlock()
+		w.Write([]byte("{}"))
+	}))
+	t.Cleanup(stub.server.Close)
+	return stub
+}
+
+func (c *collectorStub) config() models.OTLPConfiguration {
+	return models.OTLPConfiguration{
+		Endpoint:      c.server.URL + "/v1/logs",
+		ServiceName:   "billing",
+		FlushInterval: time.Hour,
+		RetryBackoff:  time.Millisecond,
+	}
+}
+
+// records returns every log record the collector accepted
+func (c *collectorStub) records() []otlpLogRecord {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var records []otlpLogRecord
+	for _, request := range c.requests {
+		for _, resourceLogs := range request.ResourceLogs {
+			for _, scopeLogs := range resourceLogs.ScopeLogs {
+				records = append(records, scopeLogs.LogRecords...)
+			}
+		}
+	}
+	return records
+}
+
+func (c *collectorStub) requestCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.requests)
+}
+
+func attribute(attributes []otlpKeyValue, key string) *otlpAnyValue {
+	for i := range attributes {
+		if attributes[i].Key == key {
+			return &attributes[i].Value
+		}
+	}
+	return nil
+}
+
+func TestOTLPWriter_ExportsBatches(t *testing.T) {
+	collector := newCollectorStub(t, nil)
+	config := collector.config()
+	config.BatchSize = 2
+	config.Headers = map[string]string{"Authorization": "Bearer token"}
+	config.ResourceAttributes = map[string]string{"deployment.environment": "test"}
+	writer := OTLPWriter(config)
+	defer writer.Close()
+
+	event := createTestLogEvent(log.WarnLevel, "corr-1", "disk nearly full")
+	event.TraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	event.SpanID = "00f067aa0ba902b7"
+	event.Error = "quota exceeded"
+	event.Caller = &models.CallerInfo{File: "disk.go", Line: 42, Function: "main.check"}
+	event.Fields["free_bytes"] = 1024
+	event.Fields["ratio"] = 0.95
+	event.Fields["mounted"] = true
+	event.Fields["tags"] = []interface{}{"ssd", 2}
+	writer.(IEventWriter).WriteEvent(&event)
+	writer.Write(marshalLogEvent(t, createTestLogEvent(log.InfoLevel, "", "second")))
+
+	deadline := time.Now().Add(time.Second)
+	for collector.requestCount() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if collector.requestCount() != 1 {
+		t.Fatalf("Expected a full batch to be exported as one request, got %d requests", collector.requestCount())
+	}
+
+	collector.mu.Lock()
+	request, headers := collector.requests[0], collector.headers[0]
+	collector.mu.Unlock()
+
+	if headers.Get("Authorization") != "Bearer token" {
+		t.Errorf("Expected the configured headers to be sent, got %v", headers)
+	}
+	resource := request.ResourceLogs[0].Resource.Attributes
+	if name := attribute(resource, "service.name"); name == nil || *name.StringValue != "billing" {
+		t.Errorf("Expected service.name billing, got %+v", resource)
+	}
+	if env := attribute(resource, "deployment.environment"); env == nil || *env.StringValue != "test" {
+		t.Errorf("Expected the resource attributes to be exported, got %+v", resource)
+	}
+	if scope := request.ResourceLogs[0].ScopeLogs[0].Scope.Name; scope != otlpScopeName {
+		t.Errorf("Expected scope %s, got %s", otlpScopeName, scope)
+	}
+
+	records := collector.records()
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+	record := records[0]
+	if record.SeverityNumber != 13 || record.SeverityText != "WARN" {
+		t.Errorf("Expected severity 13 WARN, got %d %s", record.SeverityNumber, record.SeverityText)
+	}
+	if *record.Body.StringValue != "disk nearly full" {
+		t.Errorf("Expected the message as body, got %+v", record.Body)
+	}
+	if record.TraceID != event.TraceID || record.SpanID != event.SpanID {
+		t.Errorf("Expected the trace and span IDs, got %s %s", record.TraceID, record.SpanID)
+	}
+	if record.TimeUnixNano == "" || record.ObservedTimeUnixNano == "" {
+		t.Errorf("Expected timestamps, got %q %q", record.TimeUnixNano, record.ObservedTimeUnixNano)
+	}
+
+	checks := map[string]func(*otlpAnyValue) bool{
+		"correlationid":      func(v *otlpAnyValue) bool { return *v.StringValue == "corr-1" },
+		"exception.message":  func(v *otlpAnyValue) bool { return *v.StringValue == "quota exceeded" },
+		"code.file.path":     func(v *otlpAnyValue) bool { return *v.StringValue == "disk.go" },
+		"code.line.number":   func(v *otlpAnyValue) bool { return *v.IntValue == "42" },
+		"code.function.name": func(v *otlpAnyValue) bool { return *v.StringValue == "main.check" },
+		"free_bytes":         func(v *otlpAnyValue) bool { return v.IntValue != nil && *v.IntValue == "1024" },
+		"ratio":              func(v *otlpAnyValue) bool { return v.DoubleValue != nil && *v.DoubleValue == 0.95 },
+		"mounted":            func(v *otlpAnyValue) bool { return v.BoolValue != nil && *v.BoolValue },
+		"tags": func(v *otlpAnyValue) bool {
+			return v.ArrayValue != nil && len(v.ArrayValue.Values) == 2 && *v.ArrayValue.Values[1].IntValue == "2"
+		},
+	}
+	for key, check := range checks {
+		if value := attribute(record.Attributes, key); value == nil || !check(value) {
+			t.Errorf("Unexpected attribute %s: %+v", key, value)
+		}
+	}
+
+	if records[1].SeverityNumber != 9 || records[1].TraceID != "" {
+		t.Errorf("Expected an info record without a trace ID, got %+v", records[1])
+	}
+}
+
+func TestOTLPWriter_RetriesTransientFailures(t *testing.T) {
+	collector := newCollectorStub(t, func(attempt int32) int {
+		if attempt <= 2 {
+			return http.StatusServiceUnavailable
+		}
+		return http.StatusOK
+	})
+	writer := OTLPWriter(collector.config())
+	defer writer.Close()
+
+	event := createTestLogEvent(log.InfoLevel, "", "eventually delivered")
+	writer.(IEventWriter).WriteEvent(&event)
+
+	if err := writer.(IFlusher).Flush(); err != nil {
+		t.Fatalf("Expected the export to succeed after retrying, got %v", err)
+	}
+	if attempts := collector.attempts.Load(); attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", attempts)
+	}
+	if len(collector.records()) != 1 {
+		t.Errorf("Expected the record to be delivered once, got %d", len(collector.records()))
+	}
+}
+
+func TestOTLPWriter_GivesUp(t *testing.T) {
+	t.Run("permanent failure is not retried", func(t *testing.T) {
+		collector := newCollectorStub(t, func(int32) int { return http.StatusBadRequest })
+		writer := OTLPWriter(collector.config())
+		defer writer.Close()
+
+		event := createTestLogEvent(log.InfoLevel, "", "rejected")
+		writer.(IEventWriter).WriteEvent(&event)
+
+		if err := writer.(IFlusher).Flush(); err == nil {
+			t.Error("Expected Flush to report the rejected export")
+		}
+		if attempts := collector.attempts.Load(); attempts != 1 {
+			t.Errorf("Expected a single attempt, got %d", attempts)
+		}
+	})
+
+	t.Run("retries are bounded", func(t *testing.T) {
+		collector := newCollectorStub(t, func(int32) int { return http.StatusTooManyRequests })
+		config := collector.config()
+		config.MaxRetries = 2
+		writer := OTLPWriter(config)
+		defer writer.Close()
+
+		event := createTestLogEvent(log.InfoLevel, "", "throttled")
+		writer.(IEventWriter).WriteEvent(&event)
+
+		if err := writer.(IFlusher).Flush(); err == nil {
+			t.Error("Expected Flush to report the failed export")
+		}
+		if attempts := collector.attempts.Load(); attempts != 3 {
+			t.Errorf("Expected the first attempt and 2 retries, got %d", attempts)
+		}
+
+		// The dropped batch is not sent again
+		if err := writer.(IFlusher).Flush(); err != nil {
+			t.Errorf("Expected nothing left to export, got %v", err)
+		}
+	})
+}
+
+func TestOTLPWriter_Level(t *testing.T) {
+	collector := newCollectorStub(t, nil)
+	config := collector.config()
+	config.Level = levels.WarnLevel
+	writer := OTLPWriter(config)
+	defer writer.Close()
+
+	for _, level := range []log.Level{log.DebugLevel, log.InfoLevel, log.WarnLevel, log.ErrorLevel} {
+		event := createTestLogEvent(level, "", level.String())
+		writer.(IEventWriter).WriteEvent(&event)
+	}
+	writer.(IFlusher).Flush()
+
+	if records := collector.records(); len(records) != 2 {
+		t.Errorf("Expected warn and error records, got %d", len(records))
+	}
+
+	writer.WithLevel(log.DebugLevel)
+	if writer.(ILevelReporter).GetLevel() != log.DebugLevel {
+		t.Errorf("Expected the level to change to debug")
+	}
+}
+
+func TestOTLPWriter_CloseExportsQueuedEvents(t *testing.T) {
+	collector := newCollectorStub(t, nil)
+	writer := OTLPWriter(collector.config())
+
+	for i := 0; i < 5; i++ {
+		event := createTestLogEvent(log.InfoLevel, "", "queued")
+		writer.(IEventWriter).WriteEvent(&event)
+	}
+	writer.Close()
+
+	if records := collector.records(); len(records) != 5 {
+		t.Errorf("Expected Close to export the 5 queued records, got %d", len(records))
+	}
+
+	// Events written after Close are ignored
+	event := createTestLogEvent(log.InfoLevel, "", "late")
+	writer.(IEventWriter).WriteEvent(&event)
+	if err := writer.(IFlusher).Flush(); err != nil {
+		t.Errorf("Expected Flush after Close to be a no-op, got %v", err)
+	}
+	writer.Close()
+}
+
+func TestOTelSeverity(t *testing.T) {
+	tests := []struct {
+		level  log.Level
+		number int
+		text   string
+	}{
+		{log.TraceLevel, 1, "TRACE"},
+		{log.DebugLevel, 5, "DEBUG"},
+		{log.InfoLevel, 9, "INFO"},
+		{log.WarnLevel, 13, "WARN"},
+		{log.ErrorLevel, 17, "ERROR"},
+		{log.FatalLevel, 21, "FATAL"},
+		{log.PanicLevel, 22, "PANIC"},
+	}
+
+	for _, tt := range tests {
+		number, text := OTelSeverity(tt.level)
+		if number != tt.number || text != tt.text {
+			t.Errorf("OTelSeverity(%s) = %d %s, expected %d %s", tt.level, number, text, tt.number, tt.text)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package writers
+
+import (
+	"github.com/phuslu/log"
+	"github.com/ternarybob/arbor/models"
+)
+
+// writePhusluEvent renders an arbor log event through a phuslu logger.
+// Shared by the console and file writers; phuslu discards events below the logger's level.
+func writePhusluEvent(logger *log.Logger, logEvent *models.LogEvent) {
+	var phusluEvent *log.Entry
+	switch logEvent.Level {
+	case log.TraceLevel:
+		phusluEvent = logger.Trace()
+	case log.DebugLevel:
+		phusluEvent = logger.Debug()
+	case log.InfoLevel:
+		phusluEvent = logger.Info()
+	case log.WarnLevel:
+		phusluEvent = logger.Warn()
+	case log.ErrorLevel:
+		phusluEvent = logger.Error()
+	case log.FatalLevel:
+		phusluEvent = logger.Fatal()
+	case log.PanicLevel:
+		phusluEvent = logger.Panic()
+	default:
+		phusluEvent = logger.Info()
+	}
+
+	// Below the writer's level - nothing to render
+	if phusluEvent == nil {
+		return
+	}
+
+	// Add arbor-specific fields to phuslu logger
+	if logEvent.Prefix != "" {
+		phusluEvent = phusluEvent.Str("prefix", logEvent.Prefix)
+	}
+	if logEvent.Function != "" {
+		phusluEvent = phusluEvent.Str("function", logEvent.Function)
+	}
+	if logEvent.Caller != nil {
+		phusluEvent = phusluEvent.Dict("caller", log.NewContext(nil).
+			Str("file", logEvent.Caller.File).
+			Int("line", logEvent.Caller.Line).
+			Value())
+	}
+	if logEvent.CorrelationID != "" {
+		phusluEvent = phusluEvent.Str("correlationid", logEvent.CorrelationID)
+	}
+	if logEvent.TraceID != "" {
+		phusluEvent = phusluEvent.Str("traceid", logEvent.TraceID).Str("spanid", logEvent.SpanID)
+	}
+
+	// Add custom fields from arbor
+	for key, value := range logEvent.Fields {
+		phusluEvent = appendPhusluField(phusluEvent, key, value)
+	}
+
+	// Add error if present
+	if logEvent.Error != "" {
+		phusluEvent = phusluEvent.Str("error", logEvent.Error)
+	}
+	if len(logEvent.ErrorChain) > 0 {
+		phusluEvent = phusluEvent.Any("errorchain", logEvent.ErrorChain)
+	}
+	if len(logEvent.Stack) > 0 {
+		phusluEvent = phusluEvent.Strs("stack", logEvent.Stack)
+	}
+
+	// phuslu exits or panics after writing fatal and panic entries. The level has
+	// already been rendered, so demote the entry and leave termination to arbor,
+	// which flushes every writer first.
+	if phusluEvent.Level >= log.FatalLevel {
+		phusluEvent.Level = log.ErrorLevel
+	}
+
+	// Send the message through phuslu (uses phuslu's default console format)
+	phusluEvent.Msg(logEvent.Message)
+}
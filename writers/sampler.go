@@ -0,0 +1,375 @@
+package writers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/phuslu/log"
+	"github.com/ternarybob/arbor/models"
+)
+
+// DEFAULT_SAMPLE_REPORT_INTERVAL is how often dropped event counts are reported when no interval is given
+const DEFAULT_SAMPLE_REPORT_INTERVAL = 1 * time.Minute
+
+// ISampler decides whether an event should be written.
+// Implementations must be safe for concurrent use.
+type ISampler interface {
+	Sample(event *models.LogEvent) bool
+}
+
+// burstSampler writes the first N occurrences of each level/message pair per interval,
+// then every Mth occurrence after that
+type burstSampler struct {
+	first       int
+	thereafter  int
+	interval    time.Duration
+	mu          sync.Mutex
+	windowStart time.Time
+	counts      map[string]int
+}
+
+// NewBurstSampler creates a sampler that, within each interval, keeps the first events
+// with the same level and message and then every thereafter-th one. A thereafter of 0
+// drops everything past the first events until the interval resets.
+func NewBurstSampler(first, thereafter int, interval time.Duration) ISampler {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	return &burstSampler{
+		first:      first,
+		thereafter: thereafter,
+		interval:   interval,
+		counts:     make(map[string]int),
+	}
+}
+
+func (bs *burstSampler) Sample(event *models.LogEvent) bool {
+	key := event.Level.String() + "|" + event.Message
+
+	bs.mu.Lock()
+	now := time.Now()
+	if now.Sub(bs.windowStart) >= bs.interval {
+		bs.windowStart = now
+		clear(bs.counts)
+	}
+	bs.counts[key]++
+	count := bs.counts[key]
+	bs.mu.Unlock()
+
+	if count <= bs.first {
+		return true
+	}
+	if bs.thereafter <= 0 {
+		return false
+	}
+	return (count-bs.first)%bs.thereafter == 0
+}
+
+// randomSampler keeps each event with a fixed probability
+type randomSampler struct {
+	ratio float64
+}
+
+// NewRandomSampler creates a sampler that keeps each event with the given probability (0.0 - 1.0)
+func NewRandomSampler(ratio float64) ISampler {
+	return &randomSampler{ratio: ratio}
+}
+
+func (rs *randomSampler) Sample(event *models.LogEvent) bool {
+	switch {
+	case rs.ratio >= 1:
+		return true
+	case rs.ratio <= 0:
+		return false
+	default:
+		return rand.Float64() < rs.ratio
+	}
+}
+
+// levelSampler applies a different sampler per level
+type levelSampler struct {
+	samplers map[log.Level]ISampler
+}
+
+// NewLevelSampler creates a sampler that delegates to the sampler registered for the event's level.
+// Levels without a sampler are always written.
+//
+// Example:
+//
+//	sampler := writers.NewLevelSampler(map[log.Level]writers.ISampler{
+//		log.DebugLevel: writers.NewRandomSampler(0.01),
+//		log.InfoLevel:  writers.NewBurstSampler(10, 100, time.Second),
+//	})
+func NewLevelSampler(samplers map[log.Level]ISampler) ISampler {
+	copied := make(map[log.Level]ISampler, len(samplers))
+	for level, sampler := range samplers {
+		copied[level] = sampler
+	}
+	return &levelSampler{samplers: copied}
+}
+
+func (ls *levelSampler) Sample(event *models.LogEvent) bool {
+	sampler, exists := ls.samplers[event.Level]
+	if !exists || sampler == nil {
+		return true
+	}
+	return sampler.Sample(event)
+}
+
+// SampleCounter applies a sampler and counts the events it drops, producing
+// a summary event once per report interval in which events were dropped.
+// It is shared by SampledWriter and loggers configured with a sampler.
+type SampleCounter struct {
+	sampler        ISampler
+	reportInterval time.Duration
+	mu             sync.Mutex
+	dropped        map[log.Level]uint64
+	lastReport     time.Time
+	report         func(summary *models.LogEvent) // Set by ReportTo
+	timer          *time.Timer                    // Reports outstanding drops when the interval ends
+}
+
+// pendingCounters holds the counters with outstanding drops and an armed report timer
+var (
+	pendingMux      sync.Mutex
+	pendingCounters = make(map[*SampleCounter]struct{})
+)
+
+// NewSampleCounter creates a counter for the given sampler.
+// A reportInterval of 0 or less uses DEFAULT_SAMPLE_REPORT_INTERVAL.
+func NewSampleCounter(sampler ISampler, reportInterval time.Duration) *SampleCounter {
+	if reportInterval <= 0 {
+		reportInterval = DEFAULT_SAMPLE_REPORT_INTERVAL
+	}
+	return &SampleCounter{
+		sampler:        sampler,
+		reportInterval: reportInterval,
+		dropped:        make(map[log.Level]uint64),
+		lastReport:     time.Now(),
+	}
+}
+
+// ReportTo sends summaries to report from a timer once the report interval ends, so drops are
+// reported even when no further events arrive. Allow then no longer returns summaries.
+func (sc *SampleCounter) ReportTo(report func(summary *models.LogEvent)) *SampleCounter {
+	sc.mu.Lock()
+	sc.report = report
+	sc.mu.Unlock()
+	return sc
+}
+
+// Allow reports whether the event should be written. When a report is due and
+// events were dropped since the last one, summary describes the dropped events
+// and should be written before the event itself. Counters set up with ReportTo
+// report from a timer instead and always return a nil summary.
+func (sc *SampleCounter) Allow(event *models.LogEvent) (allowed bool, summary *models.LogEvent) {
+	allowed = sc.sampler.Sample(event)
+
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	if !allowed {
+		sc.dropped[event.Level]++
+	}
+	if sc.report != nil {
+		if !allowed {
+			sc.armLocked()
+		}
+		return allowed, nil
+	}
+	if time.Since(sc.lastReport) < sc.reportInterval {
+		return allowed, nil
+	}
+	return allowed, sc.summaryLocked()
+}
+
+// Summary returns a summary of the events dropped since the last report, or nil if none were dropped.
+// Use it to report outstanding drops when shutting down.
+func (sc *SampleCounter) Summary() *models.LogEvent {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.disarmLocked()
+	return sc.summaryLocked()
+}
+
+// ReportPendingSamples immediately reports the outstanding drops of every counter set up with
+// ReportTo, rather than waiting for the end of its interval. arbor calls it from Flush and Shutdown.
+func ReportPendingSamples() {
+	pendingMux.Lock()
+	counters := make([]*SampleCounter, 0, len(pendingCounters))
+	for sc := range pendingCounters {
+		counters = append(counters, sc)
+	}
+	pendingMux.Unlock()
+
+	for _, sc := range counters {
+		sc.reportNow()
+	}
+}
+
+// reportNow writes the summary of outstanding drops through the function set with ReportTo
+func (sc *SampleCounter) reportNow() {
+	sc.mu.Lock()
+	sc.disarmLocked()
+	summary, report := sc.summaryLocked(), sc.report
+	sc.mu.Unlock()
+
+	if summary != nil && report != nil {
+		report(summary)
+	}
+}
+
+// armLocked starts the report timer for the current interval unless it is already running
+func (sc *SampleCounter) armLocked() {
+	if sc.timer != nil {
+		return
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(time.Until(sc.lastReport.Add(sc.reportInterval)), func() {
+		sc.mu.Lock()
+		current := sc.timer == timer // False once Summary or ReportPendingSamples has reported
+		sc.mu.Unlock()
+
+		if current {
+			sc.reportNow()
+		}
+	})
+	sc.timer = timer
+
+	pendingMux.Lock()
+	pendingCounters[sc] = struct{}{}
+	pendingMux.Unlock()
+}
+
+// disarmLocked stops the report timer, if running
+func (sc *SampleCounter) disarmLocked() {
+	if sc.timer == nil {
+		return
+	}
+	sc.timer.Stop()
+	sc.timer = nil
+
+	pendingMux.Lock()
+	delete(pendingCounters, sc)
+	pendingMux.Unlock()
+}
+
+func (sc *SampleCounter) summaryLocked() *models.LogEvent {
+	now := time.Now()
+	since := sc.lastReport
+	sc.lastReport = now
+
+	if len(sc.dropped) == 0 {
+		return nil
+	}
+
+	var total uint64
+	byLevel := make(map[string]interface{}, len(sc.dropped))
+	for level, count := range sc.dropped {
+		total += count
+		byLevel[level.String()] = count
+	}
+	clear(sc.dropped)
+
+	return &models.LogEvent{
+		Level:     log.InfoLevel,
+		Timestamp: now,
+		Prefix:    "sampler",
+		Message:   fmt.Sprintf("Sampling dropped %d log events", total),
+		Fields: map[string]interface{}{
+			"dropped":  total,
+			"levels":   byLevel,
+			"interval": now.Sub(since).Round(time.Millisecond).String(),
+		},
+	}
+}
+
+// sampledWriter wraps a writer, writing only the events allowed by a sampler
+type sampledWriter struct {
+	writer  IWriter
+	counter *SampleCounter
+}
+
+// SampledWriter wraps writer so that only events allowed by sampler reach it.
+// The number of dropped events is written to the same writer as a summary event at the end of
+// each reportInterval (DEFAULT_SAMPLE_REPORT_INTERVAL if 0) in which events were dropped, and
+// when the writer is flushed or closed.
+//
+// Example:
+//
+//	fileWriter := writers.FileWriter(config)
+//	arbor.RegisterWriter(arbor.WRITER_FILE, writers.SampledWriter(fileWriter, writers.NewBurstSampler(5, 100, time.Second), 0))
+func SampledWriter(writer IWriter, sampler ISampler, reportInterval time.Duration) IWriter {
+	sw := &sampledWriter{writer: writer}
+	sw.counter = NewSampleCounter(sampler, reportInterval).ReportTo(func(summary *models.LogEvent) {
+		sw.forward(summary)
+	})
+	return sw
+}
+
+// Write samples a JSON encoded event; data that is not a log event is passed through
+func (sw *sampledWriter) Write(data []byte) (int, error) {
+	var logEvent models.LogEvent
+	if err := json.Unmarshal(data, &logEvent); err != nil {
+		return sw.writer.Write(data)
+	}
+
+	if allowed, _ := sw.counter.Allow(&logEvent); !allowed {
+		return len(data), nil
+	}
+	return sw.writer.Write(data)
+}
+
+// WriteEvent samples the event and forwards it to the wrapped writer if allowed
+func (sw *sampledWriter) WriteEvent(logEvent *models.LogEvent) error {
+	if allowed, _ := sw.counter.Allow(logEvent); !allowed {
+		return nil
+	}
+	return sw.forward(logEvent)
+}
+
+// forward writes an event to the wrapped writer, encoding it for legacy writers
+func (sw *sampledWriter) forward(logEvent *models.LogEvent) error {
+	if eventWriter, ok := sw.writer.(IEventWriter); ok {
+		return eventWriter.WriteEvent(logEvent)
+	}
+	data, err := json.Marshal(logEvent)
+	if err != nil {
+		return err
+	}
+	_, err = sw.writer.Write(data)
+	return err
+}
+
+// Flush reports any outstanding dropped events, then flushes the wrapped writer if it buffers events
+func (sw *sampledWriter) Flush() error {
+	sw.counter.reportNow()
+	if flusher, ok := sw.writer.(IFlusher); ok {
+		return flusher.Flush()
+	}
+	return nil
+}
+
+func (sw *sampledWriter) WithLevel(level log.Level) IWriter {
+	sw.writer.WithLevel(level)
+	return sw
+}
+
+// GetLevel returns the wrapped writer's level, or 0 if it does not report one
+func (sw *sampledWriter) GetLevel() log.Level {
+	return WriterLevel(sw.writer)
+}
+
+func (sw *sampledWriter) GetFilePath() string {
+	return sw.writer.GetFilePath()
+}
+
+// Close reports any outstanding dropped events, then closes the wrapped writer
+func (sw *sampledWriter) Close() error {
+	sw.counter.reportNow()
+	return sw.writer.Close()
+}
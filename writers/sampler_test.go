@@ -0,0 +1,197 @@
+package writers
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/phuslu/log"
+	"github.com/ternarybob/arbor/models"
+)
+
+// recordingWriter collects the events written to it
+type recordingWriter struct {
+	mu     sync.Mutex
+	events []models.LogEvent
+	closed bool
+}
+
+func (rw *recordingWriter) WithLevel(level log.Level) IWriter { return rw }
+func (rw *recordingWriter) GetFilePath() string               { return "" }
+func (rw *recordingWriter) Write(p []byte) (int, error)       { return len(p), nil }
+
+func (rw *recordingWriter) WriteEvent(logEvent *models.LogEvent) error {
+	rw.mu.Lock()
+	rw.events = append(rw.events, *logEvent)
+	rw.mu.Unlock()
+	return nil
+}
+
+func (rw *recordingWriter) Events() []models.LogEvent {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	return append([]models.LogEvent(nil), rw.events...)
+}
+
+func (rw *recordingWriter) Close() error {
+	rw.closed = true
+	return nil
+}
+
+func sampleMany(sampler ISampler, level log.Level, message string, n int) int {
+	kept := 0
+	for i := 0; i < n; i++ {
+		event := createTestLogEvent(level, "", message)
+		if sampler.Sample(&event) {
+			kept++
+		}
+	}
+	return kept
+}
+
+func TestBurstSampler_FirstThenEveryMth(t *testing.T) {
+	sampler := NewBurstSampler(3, 10, time.Hour)
+
+	// 3 first + occurrences 13, 23, ..., 93
+	if kept := sampleMany(sampler, log.InfoLevel, "hot path", 100); kept != 12 {
+		t.Errorf("Expected 12 events kept, got %d", kept)
+	}
+
+	// Counts are tracked per level and message
+	if kept := sampleMany(sampler, log.InfoLevel, "other message", 3); kept != 3 {
+		t.Errorf("Expected a different message to get its own burst, got %d", kept)
+	}
+	if kept := sampleMany(sampler, log.DebugLevel, "hot path", 3); kept != 3 {
+		t.Errorf("Expected a different level to get its own burst, got %d", kept)
+	}
+}
+
+func TestBurstSampler_ResetsEachInterval(t *testing.T) {
+	sampler := NewBurstSampler(2, 0, 20*time.Millisecond)
+
+	if kept := sampleMany(sampler, log.InfoLevel, "burst", 10); kept != 2 {
+		t.Errorf("Expected 2 events kept, got %d", kept)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+
+	if kept := sampleMany(sampler, log.InfoLevel, "burst", 10); kept != 2 {
+		t.Errorf("Expected burst to reset after the interval, got %d", kept)
+	}
+}
+
+func TestRandomSampler_Bounds(t *testing.T) {
+	if kept := sampleMany(NewRandomSampler(1), log.InfoLevel, "all", 100); kept != 100 {
+		t.Errorf("Expected ratio 1 to keep everything, got %d", kept)
+	}
+	if kept := sampleMany(NewRandomSampler(0), log.InfoLevel, "none", 100); kept != 0 {
+		t.Errorf("Expected ratio 0 to drop everything, got %d", kept)
+	}
+	if kept := sampleMany(NewRandomSampler(0.5), log.InfoLevel, "half", 10000); kept < 4000 || kept > 6000 {
+		t.Errorf("Expected roughly half of the events kept, got %d", kept)
+	}
+}
+
+func TestLevelSampler(t *testing.T) {
+	sampler := NewLevelSampler(map[log.Level]ISampler{
+		log.DebugLevel: NewRandomSampler(0),
+	})
+
+	if kept := sampleMany(sampler, log.DebugLevel, "debug", 10); kept != 0 {
+		t.Errorf("Expected debug events to be dropped, got %d", kept)
+	}
+	if kept := sampleMany(sampler, log.ErrorLevel, "error", 10); kept != 10 {
+		t.Errorf("Expected levels without a sampler to be kept, got %d", kept)
+	}
+}
+
+func TestSampledWriter_ReportsDroppedEvents(t *testing.T) {
+	recorder := &recordingWriter{}
+	writer := SampledWriter(recorder, NewBurstSampler(1, 0, time.Hour), 20*time.Millisecond)
+
+	for i := 0; i < 5; i++ {
+		event := createTestLogEvent(log.DebugLevel, "", "repeated")
+		writer.(IEventWriter).WriteEvent(&event)
+	}
+	if len(recorder.Events()) != 1 {
+		t.Fatalf("Expected only the first event written, got %d", len(recorder.Events()))
+	}
+
+	// The summary is written when the interval ends, without waiting for another event
+	time.Sleep(60 * time.Millisecond)
+
+	events := recorder.Events()
+	if len(events) != 2 {
+		t.Fatalf("Expected the summary written after the interval, got %d events", len(events))
+	}
+	summary := events[1]
+	if summary.Prefix != "sampler" || summary.Fields["dropped"] != uint64(4) {
+		t.Errorf("Expected summary of 4 dropped events, got %+v", summary)
+	}
+	if levels, ok := summary.Fields["levels"].(map[string]interface{}); !ok || levels["debug"] != uint64(4) {
+		t.Errorf("Expected dropped counts by level, got %v", summary.Fields["levels"])
+	}
+
+	// No further summaries without further drops
+	time.Sleep(60 * time.Millisecond)
+	if len(recorder.Events()) != 2 {
+		t.Errorf("Expected no summary for an interval without drops, got %d events", len(recorder.Events()))
+	}
+}
+
+func TestSampledWriter_FlushReportsOutstandingDrops(t *testing.T) {
+	recorder := &recordingWriter{}
+	writer := SampledWriter(recorder, NewRandomSampler(0), time.Hour)
+
+	for i := 0; i < 2; i++ {
+		event := createTestLogEvent(log.InfoLevel, "", "dropped")
+		writer.(IEventWriter).WriteEvent(&event)
+	}
+	if err := writer.(IFlusher).Flush(); err != nil {
+		t.Fatalf("Flush should not return error: %v", err)
+	}
+
+	events := recorder.Events()
+	if len(events) != 1 || events[0].Fields["dropped"] != uint64(2) {
+		t.Errorf("Expected a summary of 2 dropped events on flush, got %+v", events)
+	}
+}
+
+func TestReportPendingSamples(t *testing.T) {
+	var reported []*models.LogEvent
+	counter := NewSampleCounter(NewRandomSampler(0), time.Hour).ReportTo(func(summary *models.LogEvent) {
+		reported = append(reported, summary)
+	})
+
+	event := createTestLogEvent(log.WarnLevel, "", "dropped")
+	if allowed, summary := counter.Allow(&event); allowed || summary != nil {
+		t.Fatalf("Expected the event dropped without an inline summary, got %v %v", allowed, summary)
+	}
+
+	ReportPendingSamples()
+	ReportPendingSamples()
+
+	if len(reported) != 1 || reported[0].Fields["dropped"] != uint64(1) {
+		t.Errorf("Expected one summary of 1 dropped event, got %+v", reported)
+	}
+}
+
+func TestSampledWriter_CloseReportsOutstandingDrops(t *testing.T) {
+	recorder := &recordingWriter{}
+	writer := SampledWriter(recorder, NewRandomSampler(0), time.Hour)
+
+	for i := 0; i < 3; i++ {
+		event := createTestLogEvent(log.InfoLevel, "", "dropped")
+		writer.(IEventWriter).WriteEvent(&event)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close should not return error: %v", err)
+	}
+
+	if len(recorder.events) != 1 || recorder.events[0].Fields["dropped"] != uint64(3) {
+		t.Errorf("Expected a summary of 3 dropped events on close, got %+v", recorder.events)
+	}
+	if !recorder.closed {
+		t.Error("Expected the wrapped writer to be closed")
+	}
+}